@@ -0,0 +1,272 @@
+// Command pocketlog reads the JSON lines written by pocketlog loggers, filters them, and prints them as text.
+//
+// Usage:
+//
+//	pocketlog [flags] [file ...]
+//
+// It reads the standard input when no file is given, or for the file "-".
+// Filters on fields are written key=value, or key~regexp, and the keys "logger" and "message"
+// match the name and the message of entries. Times are RFC 3339 timestamps, or durations before now, like 1h30m.
+//
+// With -f, files are followed: the entries written to them are printed until the command is interrupted.
+// When a filter is set with -level, -since, -until or -field, the existing entries that match are printed
+// first; otherwise only the entries written after the command starts are printed.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogquery"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// followInterval is how often followed files are checked for new entries.
+const followInterval = 250 * time.Millisecond
+
+// timeLayout is the layout of the timestamps of printed entries.
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		// a second interrupt stops the command while it waits for stdin
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "pocketlog:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the arguments, and prints the matching entries of the inputs to stdout.
+// Invalid lines are reported to stderr, and skipped.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pocketlog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var fields fieldFilters
+	level := flags.String("level", "debug", "print entries at or above this `level`")
+	since := flags.String("since", "", "print entries logged at or after this `time`")
+	until := flags.String("until", "", "print entries logged before this `time`")
+	follow := flags.Bool("f", false, "keep printing the entries of files as they are written; without filters, only new entries are printed")
+	color := flags.String("color", "auto", "color levels: auto, always or never")
+	flags.Var(&fields, "field", "print entries with a field that matches `key=value` or key~regexp (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	lvl, err := pocketlog.ParseLevel(*level)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from, err := parseTime(*since, now)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	to, err := parseTime(*until, now)
+	if err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+
+	mode, err := parseColorMode(*color)
+	if err != nil {
+		return err
+	}
+
+	p := &printer{
+		filter: pocketlogquery.All(append([]pocketlogquery.Filter{
+			pocketlogquery.AtLeast(lvl),
+			pocketlogquery.Between(from, to),
+		}, fields...)...),
+		sink:   pocketlog.NewWriterSink(stdout, pocketlog.LevelDebug, pocketlog.WithTimestamp(timeLayout), pocketlog.WithColor(mode)),
+		stderr: stderr,
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	if !*follow {
+		for _, path := range paths {
+			if err := p.printFile(path, stdin); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// with filters, the existing entries that match are printed before the new ones
+	filtered := false
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "level", "since", "until", "field":
+			filtered = true
+		}
+	})
+
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			errs[i] = p.followFile(ctx, path, filtered, stdin)
+		}(i, path)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// printer prints the records that match its filter.
+type printer struct {
+	filter pocketlogquery.Filter
+	sink   pocketlog.Sink
+	// mu serializes the output of followed files.
+	mu     sync.Mutex
+	stderr io.Writer
+}
+
+// printFile prints the records of the file at path, or of stdin for "-".
+func (p *printer) printFile(path string, stdin io.Reader) error {
+	if path == "-" {
+		return p.print("stdin", stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return p.print(path, f)
+}
+
+// followFile prints the records of the file at path, or of stdin for "-", until ctx is done.
+// The existing records of the file are printed only if fromStart is set.
+func (p *printer) followFile(ctx context.Context, path string, fromStart bool, stdin io.Reader) error {
+	if path == "-" {
+		// stdin already waits for more data
+		return p.print("stdin", stdin)
+	}
+
+	follow := pocketlogquery.Follow
+	if fromStart {
+		follow = pocketlogquery.FollowFromStart
+	}
+
+	r, err := follow(ctx, path, followInterval)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return p.print(path, r)
+}
+
+// print prints the records of r that match the filter.
+func (p *printer) print(name string, r io.Reader) error {
+	reader := pocketlogquery.NewReader(r)
+	for {
+		rec, err := reader.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.Is(err, pocketlogquery.ErrInvalidRecord):
+			p.mu.Lock()
+			fmt.Fprintf(p.stderr, "pocketlog: %s: %s\n", name, err)
+			p.mu.Unlock()
+			continue
+		case err != nil:
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if !p.filter(rec) {
+			continue
+		}
+
+		e := rec.Entry
+		if rec.Caller != "" {
+			e.Fields = append(e.Fields, pocketlog.Any("caller", rec.Caller))
+		}
+
+		p.mu.Lock()
+		err = p.sink.Log(e)
+		p.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fieldFilters collects the filters of the -field flags.
+type fieldFilters []pocketlogquery.Filter
+
+// String implements the flag.Value interface.
+func (ff *fieldFilters) String() string {
+	return ""
+}
+
+// Set implements the flag.Value interface. It parses key=value and key~regexp filters.
+func (ff *fieldFilters) Set(value string) error {
+	i := strings.IndexAny(value, "=~")
+	if i <= 0 {
+		return fmt.Errorf("expected key=value or key~regexp, got %q", value)
+	}
+
+	key := value[:i]
+	if value[i] == '=' {
+		*ff = append(*ff, pocketlogquery.FieldEquals(key, value[i+1:]))
+		return nil
+	}
+
+	re, err := regexp.Compile(value[i+1:])
+	if err != nil {
+		return err
+	}
+	*ff = append(*ff, pocketlogquery.FieldMatches(key, re))
+	return nil
+}
+
+// parseTime parses an RFC 3339 timestamp, or a duration before now. It returns the zero time for an empty value.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// parseColorMode parses the value of the -color flag.
+func parseColorMode(value string) (pocketlog.ColorMode, error) {
+	switch value {
+	case "auto":
+		return pocketlog.ColorAuto, nil
+	case "always":
+		return pocketlog.ColorAlways, nil
+	case "never":
+		return pocketlog.ColorNever, nil
+	default:
+		return 0, fmt.Errorf("invalid color mode %q, expected auto, always or never", value)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const input = `{"time":"2024-03-01T12:00:00Z","level":"debug","message":"starting"}
+{"time":"2024-03-01T12:00:01Z","level":"info","logger":"http","message":"request","fields":{"path":"/users","status":200}}
+oops
+{"time":"2024-03-01T12:00:02Z","level":"error","logger":"http","caller":"http/server.go:12","message":"request","fields":{"path":"/admin","status":500}}
+`
+
+func TestRun(t *testing.T) {
+	tt := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"all": {
+			args: []string{"-color", "never"},
+			expected: "2024-03-01T12:00:00.000Z DEBUG:\tstarting\n" +
+				"2024-03-01T12:00:01.000Z INFO:\thttp: request path=/users status=200\n" +
+				"2024-03-01T12:00:02.000Z ERROR:\thttp: request path=/admin status=500 caller=http/server.go:12\n",
+		},
+		"level": {
+			args:     []string{"-level", "error"},
+			expected: "2024-03-01T12:00:02.000Z ERROR:\thttp: request path=/admin status=500 caller=http/server.go:12\n",
+		},
+		"time range": {
+			args:     []string{"-since", "2024-03-01T12:00:01Z", "-until", "2024-03-01T12:00:02Z"},
+			expected: "2024-03-01T12:00:01.000Z INFO:\thttp: request path=/users status=200\n",
+		},
+		"fields": {
+			args:     []string{"-field", "logger=http", "-field", "status~^2"},
+			expected: "2024-03-01T12:00:01.000Z INFO:\thttp: request path=/users status=200\n",
+		},
+		"color": {
+			args:     []string{"-color", "always", "-level", "error", "-"},
+			expected: "2024-03-01T12:00:02.000Z \x1b[31mERROR:\x1b[0m\thttp: request path=/admin status=500 caller=http/server.go:12\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			if err := run(context.Background(), tc.args, strings.NewReader(input), &stdout, &stderr); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if stdout.String() != tc.expected {
+				t.Errorf("invalid output, expected %q, got %q", tc.expected, stdout.String())
+			}
+			if expected := "pocketlog: stdin: line 3: invalid record"; !strings.HasPrefix(stderr.String(), expected) {
+				t.Errorf("invalid errors, expected prefix %q, got %q", expected, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_InvalidFlags(t *testing.T) {
+	tt := map[string][]string{
+		"level":  {"-level", "loud"},
+		"since":  {"-since", "yesterday"},
+		"field":  {"-field", "path"},
+		"regexp": {"-field", "path~("},
+		"color":  {"-color", "rainbow"},
+	}
+
+	for name, args := range tt {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements the io.Writer interface
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func TestRun_Follow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout, stderr syncBuffer
+	done := make(chan error)
+	go func() {
+		done <- run(ctx, []string{"-f", "-color", "never", "-level", "error", path}, strings.NewReader(""), &stdout, &stderr)
+	}()
+
+	// with a filter, the existing entries that match are printed before the new ones
+	existing := "2024-03-01T12:00:02.000Z ERROR:\thttp: request path=/admin status=500 caller=http/server.go:12\n"
+	waitForOutput(t, &stdout, existing)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("unable to open file: %s", err)
+	}
+	_, err = f.WriteString(`{"time":"2024-03-01T12:00:03Z","level":"info","message":"skipped"}` + "\n" +
+		`{"time":"2024-03-01T12:00:04Z","level":"error","message":"appended"}` + "\n")
+	f.Close()
+	if err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	expected := existing + "2024-03-01T12:00:04.000Z ERROR:\tappended\n"
+	waitForOutput(t, &stdout, expected)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stdout.String() != expected {
+		t.Errorf("invalid output, expected %q, got %q", expected, stdout.String())
+	}
+}
+
+// waitForOutput waits until the output is the expected one.
+func waitForOutput(t *testing.T, output *syncBuffer, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for output.String() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for output %q, got %q", expected, output.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,154 @@
+package pocketlog
+
+import "sync"
+
+// OverflowPolicy defines what an asynchronous logger does with an entry when its queue is full.
+type OverflowPolicy byte
+
+const (
+	// OverflowBlock makes the caller wait until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the entry being logged.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued entry to make room for the new one.
+	OverflowDropOldest
+)
+
+// asyncQueue holds entries until a background goroutine writes them.
+type asyncQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// entries is a ring buffer of count entries, starting at head.
+	entries []Entry
+	head    int
+	count   int
+
+	policy  OverflowPolicy
+	dropped uint64
+	// waiting counts the callers waiting for room in the queue.
+	waiting int
+	// writing is true while the background goroutine writes an entry it took out of the queue.
+	writing bool
+	closed  bool
+	done    chan struct{}
+
+	write func(Entry)
+}
+
+// newAsyncQueue starts a goroutine that passes queued entries to write, in order.
+func newAsyncQueue(size int, policy OverflowPolicy, write func(Entry)) *asyncQueue {
+	q := &asyncQueue{
+		entries: make([]Entry, size),
+		policy:  policy,
+		done:    make(chan struct{}),
+		write:   write,
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.run()
+
+	return q
+}
+
+// push queues the entry, applying the overflow policy if the queue is full.
+// Fatal entries are never dropped: they wait for room whatever the policy.
+// Once the queue is closed, entries are written synchronously.
+func (q *asyncQueue) push(e Entry) {
+	q.mu.Lock()
+
+	for q.count == len(q.entries) && !q.closed {
+		policy := q.policy
+		if e.Level >= LevelFatal {
+			policy = OverflowBlock
+		}
+
+		switch policy {
+		case OverflowDropNewest:
+			q.dropped++
+			q.mu.Unlock()
+			return
+		case OverflowDropOldest:
+			q.entries[q.head] = Entry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.count--
+			q.dropped++
+		default:
+			q.waiting++
+			q.cond.Wait()
+			q.waiting--
+		}
+	}
+
+	if q.closed {
+		q.mu.Unlock()
+		q.write(e)
+		return
+	}
+
+	q.entries[(q.head+q.count)%len(q.entries)] = e
+	q.count++
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// run writes entries until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		for q.count == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.count == 0 {
+			return
+		}
+
+		e := q.entries[q.head]
+		q.entries[q.head] = Entry{}
+		q.head = (q.head + 1) % len(q.entries)
+		q.count--
+		q.writing = true
+		// wake up producers waiting for room
+		q.cond.Broadcast()
+
+		q.mu.Unlock()
+		q.write(e)
+		q.mu.Lock()
+
+		q.writing = false
+		q.cond.Broadcast()
+	}
+}
+
+// flush waits until every queued entry has been written.
+func (q *asyncQueue) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.count > 0 || q.writing {
+		q.cond.Wait()
+	}
+}
+
+// close writes the remaining entries and stops the background goroutine.
+// It is safe to call close more than once.
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	<-q.done
+}
+
+// droppedCount returns the number of entries discarded by the overflow policy.
+func (q *asyncQueue) droppedCount() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.dropped
+}
@@ -0,0 +1,200 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks every Write until the gate is closed,
+// and signals on started each time a Write begins.
+type gatedWriter struct {
+	gate    chan struct{}
+	started chan struct{}
+
+	mu       sync.Mutex
+	contents string
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), started: make(chan struct{}, 100)}
+}
+
+// Write implements the io.Writer interface
+func (gw *gatedWriter) Write(p []byte) (n int, err error) {
+	gw.started <- struct{}{}
+	<-gw.gate
+
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	gw.contents += string(p)
+	return len(p), nil
+}
+
+func (gw *gatedWriter) String() string {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	return gw.contents
+}
+
+func TestLogger_WithAsync_OverflowPolicy(t *testing.T) {
+	type testCase struct {
+		policy   pocketlog.OverflowPolicy
+		expected string
+		dropped  uint64
+	}
+
+	tt := map[string]testCase{
+		"drop newest": {
+			policy:   pocketlog.OverflowDropNewest,
+			expected: "INFO:\t1\nINFO:\t2\nINFO:\t3\n",
+			dropped:  1,
+		},
+		"drop oldest": {
+			policy:   pocketlog.OverflowDropOldest,
+			expected: "INFO:\t1\nINFO:\t3\nINFO:\t4\n",
+			dropped:  1,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			gw := newGatedWriter()
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(gw),
+				pocketlog.WithAsync(2), pocketlog.WithOverflowPolicy(tc.policy))
+			defer testedLogger.Close()
+
+			// the background goroutine takes the first entry out of the queue and blocks on it
+			testedLogger.Infof("1")
+			<-gw.started
+
+			// fill the queue, then overflow it
+			testedLogger.Infof("2")
+			testedLogger.Infof("3")
+			testedLogger.Infof("4")
+
+			close(gw.gate)
+			testedLogger.Flush()
+
+			if got := gw.String(); got != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, got)
+			}
+			if got := testedLogger.Dropped(); got != tc.dropped {
+				t.Errorf("expected %d dropped entries, got %d", tc.dropped, got)
+			}
+		})
+	}
+}
+
+func TestLogger_WithAsync_Block(t *testing.T) {
+	gw := newGatedWriter()
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(gw), pocketlog.WithAsync(1))
+
+	testedLogger.Infof("1")
+	<-gw.started
+	testedLogger.Infof("2")
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		testedLogger.Infof("3")
+	}()
+
+	waitForBlockedCaller(t, testedLogger, logged)
+
+	close(gw.gate)
+	select {
+	case <-logged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the caller to return once the queue has room")
+	}
+	testedLogger.Close()
+
+	const expected = "INFO:\t1\nINFO:\t2\nINFO:\t3\n"
+	if got := gw.String(); got != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, got)
+	}
+	if got := testedLogger.Dropped(); got != 0 {
+		t.Errorf("expected no dropped entries, got %d", got)
+	}
+}
+
+func TestLogger_WithAsync_Fatal(t *testing.T) {
+	gw := newGatedWriter()
+	exited := make(chan int, 1)
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(gw), pocketlog.WithAsync(1),
+		pocketlog.WithOverflowPolicy(pocketlog.OverflowDropNewest), pocketlog.WithExitFunc(func(code int) { exited <- code }))
+	defer testedLogger.Close()
+
+	testedLogger.Infof("1")
+	<-gw.started
+	testedLogger.Infof("2")
+
+	// the queue is full, yet the fatal entry isn't dropped
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		testedLogger.Fatalf("3")
+	}()
+
+	waitForBlockedCaller(t, testedLogger, logged)
+	close(gw.gate)
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Fatalf to exit once the queue has room")
+	}
+
+	const expected = "INFO:\t1\nINFO:\t2\nFATAL:\t3\n"
+	if got := gw.String(); got != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, got)
+	}
+	if got := testedLogger.Dropped(); got != 0 {
+		t.Errorf("expected no dropped entries, got %d", got)
+	}
+}
+
+// waitForBlockedCaller waits until a caller of the asynchronous logger waits for room in its queue,
+// and checks that the caller, which closes logged when it returns, didn't return.
+func waitForBlockedCaller(t *testing.T, lgr *pocketlog.Logger, logged <-chan struct{}) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pocketlog.QueueWaiting(lgr) == 0 {
+		select {
+		case <-logged:
+			t.Fatal("expected the caller to block while the queue is full")
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the caller to block")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLogger_Close(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithAsync(100))
+
+	child := testedLogger.With("child", true)
+	for i := 0; i < 50; i++ {
+		child.Debugf("%d", i)
+	}
+
+	testedLogger.Close()
+	testedLogger.Close()
+
+	// entries logged after Close are still written
+	child.Infof("after close")
+
+	if got := strings.Count(tw.contents, "\n"); got != 51 {
+		t.Errorf("expected 51 entries, got %d", got)
+	}
+	if !strings.HasSuffix(tw.contents, "INFO:\tafter close child=true\n") {
+		t.Errorf("unexpected last entry in %q", tw.contents)
+	}
+}
@@ -0,0 +1,68 @@
+package pocketlog_test
+
+import (
+	"io"
+	"log/pocketlog"
+	"testing"
+	"time"
+)
+
+// benchPath is a variable, so that the compiler can't turn it into a constant interface.
+var benchPath = "/users"
+
+func BenchmarkLogger_Disabled(b *testing.B) {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard))
+
+	b.Run("Debugf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lgr.Debugf("request %s took %v", benchPath, time.Duration(i))
+		}
+	})
+
+	b.Run("Debugw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lgr.Debugw("request", "path", benchPath, "duration", time.Duration(i), "status", 200+i%300)
+		}
+	})
+
+	b.Run("Debug", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lgr.Debug("request", pocketlog.String("path", benchPath), pocketlog.Duration("duration", time.Duration(i)),
+				pocketlog.Int("status", 200+i%300))
+		}
+	})
+}
+
+func BenchmarkLogger_Enabled(b *testing.B) {
+	formats := map[string]pocketlog.Format{"text": pocketlog.FormatText, "json": pocketlog.FormatJSON}
+
+	for name, format := range formats {
+		lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard), pocketlog.WithFormat(format)).
+			With("service", "users")
+
+		b.Run(name+"/Infof", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lgr.Infof("request %s took %v", benchPath, time.Duration(i))
+			}
+		})
+
+		b.Run(name+"/Infow", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lgr.Infow("request", "path", benchPath, "duration", time.Duration(i), "status", 200+i%300)
+			}
+		})
+
+		b.Run(name+"/Info", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lgr.Info("request", pocketlog.String("path", benchPath), pocketlog.Duration("duration", time.Duration(i)),
+					pocketlog.Int("status", 200+i%300))
+			}
+		})
+	}
+}
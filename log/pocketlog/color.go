@@ -0,0 +1,76 @@
+package pocketlog
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode defines whether text entries are colored with ANSI escape codes.
+type ColorMode byte
+
+const (
+	// ColorAuto colors entries written to a terminal, unless the NO_COLOR environment variable is set.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors entries, whatever the output.
+	ColorAlways
+	// ColorNever never colors entries.
+	ColorNever
+)
+
+// ANSI escape codes used to color the level of text entries.
+const (
+	colorReset   = "\x1b[0m"
+	colorGray    = "\x1b[90m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorRed     = "\x1b[31m"
+	colorBoldRed = "\x1b[1;31m"
+)
+
+// color returns the escape code that starts the prefix of the level.
+func (lvl Level) color() string {
+	switch lvl {
+	case LevelDebug:
+		return colorGray
+	case LevelInfo:
+		return colorGreen
+	case LevelWarn:
+		return colorYellow
+	case LevelError:
+		return colorRed
+	default:
+		return colorBoldRed
+	}
+}
+
+// colorOutput reports whether entries written to output should be colored in the given mode.
+func colorOutput(mode ColorMode, output io.Writer) bool {
+	return useColor(mode, isTerminal(output), os.Getenv("NO_COLOR"))
+}
+
+// useColor reports whether to color entries in the given mode.
+// As per https://no-color.org, a NO_COLOR variable that isn't empty disables automatic colors.
+func useColor(mode ColorMode, terminal bool, noColor string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		return terminal && noColor == ""
+	}
+}
+
+// isTerminal reports whether output is a file connected to a terminal.
+func isTerminal(output io.Writer) bool {
+	f, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,27 @@
+package pocketlog
+
+import "testing"
+
+func TestUseColor(t *testing.T) {
+	tt := map[string]struct {
+		mode     ColorMode
+		terminal bool
+		noColor  string
+		expected bool
+	}{
+		"auto on a terminal":       {mode: ColorAuto, terminal: true, expected: true},
+		"auto on a non-terminal":   {mode: ColorAuto, terminal: false, expected: false},
+		"auto with NO_COLOR":       {mode: ColorAuto, terminal: true, noColor: "1", expected: false},
+		"always on a non-terminal": {mode: ColorAlways, terminal: false, expected: true},
+		"always with NO_COLOR":     {mode: ColorAlways, terminal: true, noColor: "1", expected: true},
+		"never on a terminal":      {mode: ColorNever, terminal: true, expected: false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := useColor(tc.mode, tc.terminal, tc.noColor); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
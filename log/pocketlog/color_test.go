@@ -0,0 +1,91 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"os"
+	"testing"
+)
+
+func TestLogger_WithColor(t *testing.T) {
+	tt := map[string]struct {
+		mode     pocketlog.ColorMode
+		expected string
+	}{
+		"auto on a non-terminal": {
+			mode:     pocketlog.ColorAuto,
+			expected: "DEBUG:\t" + debugMessage + "\n" + "WARN:\t" + infoMessage + "\n" + "FATAL:\t" + errorMessage + "\n",
+		},
+		"never": {
+			mode:     pocketlog.ColorNever,
+			expected: "DEBUG:\t" + debugMessage + "\n" + "WARN:\t" + infoMessage + "\n" + "FATAL:\t" + errorMessage + "\n",
+		},
+		"always": {
+			mode: pocketlog.ColorAlways,
+			expected: "\x1b[90mDEBUG:\x1b[0m\t" + debugMessage + "\n" +
+				"\x1b[33mWARN:\x1b[0m\t" + infoMessage + "\n" +
+				"\x1b[1;31mFATAL:\x1b[0m\t" + errorMessage + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithColor(tc.mode),
+				pocketlog.WithExitFunc(func(int) {}))
+
+			testedLogger.Debugf(debugMessage)
+			testedLogger.Warnf(infoMessage)
+			testedLogger.Fatalf(errorMessage)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestNewWriterSink_UnknownLevel(t *testing.T) {
+	tt := map[string]struct {
+		mode     pocketlog.ColorMode
+		expected string
+	}{
+		"never":  {mode: pocketlog.ColorNever, expected: "LEVEL(9):\t" + infoMessage + "\n"},
+		"always": {mode: pocketlog.ColorAlways, expected: "\x1b[1;31mLEVEL(9):\x1b[0m\t" + infoMessage + "\n"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			sink := pocketlog.NewWriterSink(tw, pocketlog.LevelDebug, pocketlog.WithColor(tc.mode))
+			if err := sink.Log(pocketlog.Entry{Level: 9, Message: infoMessage}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_WithColor_Pipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unable to create pipe: %s", err)
+	}
+	defer r.Close()
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(w))
+	testedLogger.Errorf(errorMessage)
+	_ = w.Close()
+
+	buf := make([]byte, 256)
+	n, _ := r.Read(buf)
+
+	expected := "ERROR:\t" + errorMessage + "\n"
+	if string(buf[:n]) != expected {
+		t.Errorf("expected piped output to stay %q, got %q", expected, buf[:n])
+	}
+}
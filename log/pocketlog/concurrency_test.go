@@ -0,0 +1,105 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writesRecorder keeps every call to Write separately.
+// It is not safe for concurrent use on purpose: the logger must serialize its writes.
+type writesRecorder struct {
+	writes []string
+}
+
+// Write implements the io.Writer interface
+func (wr *writesRecorder) Write(p []byte) (n int, err error) {
+	wr.writes = append(wr.writes, string(p))
+	return len(p), nil
+}
+
+func TestLogger_Concurrency(t *testing.T) {
+	const (
+		goroutines = 50
+		entries    = 200
+	)
+
+	tt := map[string][]pocketlog.Option{
+		"text":  {pocketlog.WithFormat(pocketlog.FormatText)},
+		"json":  {pocketlog.WithFormat(pocketlog.FormatJSON)},
+		"async": {pocketlog.WithAsync(16)},
+	}
+
+	for name, opts := range tt {
+		t.Run(name, func(t *testing.T) {
+			wr := &writesRecorder{}
+			testedLogger := pocketlog.New(pocketlog.LevelDebug, append(opts, pocketlog.WithOutput(wr))...)
+
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+
+					// children share the parent's output, so they must share its lock too
+					child := testedLogger.With("goroutine", g)
+					for i := 0; i < entries; i++ {
+						switch i % 3 {
+						case 0:
+							child.Debugf("%s %d", debugMessage, i)
+						case 1:
+							child.Infow(infoMessage, "i", i)
+						default:
+							testedLogger.Errorf("%s %d-%d", errorMessage, g, i)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			testedLogger.Close()
+
+			if len(wr.writes) != goroutines*entries {
+				t.Fatalf("expected %d writes, got %d", goroutines*entries, len(wr.writes))
+			}
+
+			for _, w := range wr.writes {
+				if strings.Count(w, "\n") != 1 || !strings.HasSuffix(w, "\n") {
+					t.Fatalf("expected each write to hold exactly one entry, got %q", w)
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_SetLevel_Concurrency(t *testing.T) {
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(&testWriter{}))
+	named := testedLogger.Named("db")
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					named.Debugf(debugMessage)
+					_ = testedLogger.Enabled(pocketlog.LevelInfo)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		lvl := pocketlog.Level(i % int(pocketlog.LevelFatal+1))
+		testedLogger.SetLevel(lvl)
+		testedLogger.SetNamedLevel("db", lvl)
+		testedLogger.UnsetNamedLevel("db")
+	}
+	close(done)
+	wg.Wait()
+}
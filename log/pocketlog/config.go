@@ -0,0 +1,519 @@
+package pocketlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is returned by FromConfig for configurations it can't build a Logger from.
+const ErrInvalidConfig = pocketlogError("invalid config")
+
+// Environment variables that override the configuration file.
+// Rotation, sampling and named levels can only be set in the file.
+const (
+	// EnvLevel holds the threshold of the logger.
+	EnvLevel = "POCKETLOG_LEVEL"
+	// EnvFormat holds the format of entries, text, json or logfmt.
+	EnvFormat = "POCKETLOG_FORMAT"
+	// EnvOutputs holds a comma-separated list of outputs, which replaces the outputs of the file.
+	EnvOutputs = "POCKETLOG_OUTPUTS"
+)
+
+// config is the content of a configuration file.
+type config struct {
+	Level       string            `json:"level" yaml:"level"`
+	Format      string            `json:"format" yaml:"format"`
+	Timestamp   string            `json:"timestamp" yaml:"timestamp"`
+	UTC         bool              `json:"utc" yaml:"utc"`
+	Caller      bool              `json:"caller" yaml:"caller"`
+	NamedLevels map[string]string `json:"named_levels" yaml:"named_levels"`
+	Outputs     []outputConfig    `json:"outputs" yaml:"outputs"`
+	Sampling    *samplingConfig   `json:"sampling" yaml:"sampling"`
+}
+
+// outputConfig describes where some entries are written.
+type outputConfig struct {
+	Path     string          `json:"path" yaml:"path"`
+	Level    string          `json:"level" yaml:"level"`
+	Format   string          `json:"format" yaml:"format"`
+	Rotation *rotationConfig `json:"rotation" yaml:"rotation"`
+}
+
+// rotationConfig describes when the file of an output is rotated.
+type rotationConfig struct {
+	MaxSize    string `json:"max_size" yaml:"max_size"`
+	Interval   string `json:"interval" yaml:"interval"`
+	MaxBackups int    `json:"max_backups" yaml:"max_backups"`
+	Compress   bool   `json:"compress" yaml:"compress"`
+}
+
+// samplingConfig holds the arguments of WithSampling.
+type samplingConfig struct {
+	First      int    `json:"first" yaml:"first"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+	Interval   string `json:"interval" yaml:"interval"`
+}
+
+// FromConfig returns a logger described by the YAML or JSON file at path, depending on its extension,
+// and by the EnvLevel, EnvFormat and EnvOutputs environment variables, which take precedence.
+// An empty path only reads the environment. The options are applied after the configuration:
+// WithOutput replaces its outputs, and WithFormat, WithTimestamp, WithPrefix and WithColor apply to each of them.
+// The file looks like this, and every key is optional:
+//
+//	level: info                # the threshold, info by default
+//	format: json               # text, the default, json or logfmt
+//	timestamp: "15:04:05.000"  # the time layout of text entries, which have no time by default
+//	utc: true
+//	caller: true
+//	named_levels:
+//	  db: debug
+//	outputs:                   # the standard output by default
+//	  - path: stderr           # stdout, stderr, or the path of a file
+//	    level: error           # only write entries at or above this level
+//	  - path: /var/log/app.log
+//	    format: text           # the format of the logger by default
+//	    rotation:
+//	      max_size: 100MB      # in bytes, or with a KB, MB or GB suffix
+//	      interval: 24h
+//	      max_backups: 7
+//	      compress: true
+//	sampling:
+//	  first: 10
+//	  thereafter: 100
+//	  interval: 1s
+//
+// Errors wrap ErrInvalidConfig and name the offending key or environment variable.
+// Close closes the files that the logger opened.
+func FromConfig(path string, opts ...Option) (*Logger, error) {
+	var cfg config
+	if path != "" {
+		if err := readConfig(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return cfg.build(opts)
+}
+
+// readConfig decodes the file at path into cfg. Unknown keys are errors.
+func readConfig(path string, cfg *config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+		if errors.Is(err, io.EOF) {
+			// empty file
+			err = nil
+		}
+	default:
+		return fmt.Errorf("%w: unsupported file extension %q, expected .json, .yaml or .yml", ErrInvalidConfig, ext)
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return yamlTypeErrors(path, data, typeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+	}
+	return nil
+}
+
+// yamlErrorPattern matches the errors of a yaml.TypeError, such as "line 3: cannot unmarshal !!str `abc` into int".
+var yamlErrorPattern = regexp.MustCompile("^line ([0-9]+): (.*)$")
+
+// yamlTypeErrors returns the errors of a yaml.TypeError, which only tell lines, as errors
+// that name the keys of the document at path that they are about.
+func yamlTypeErrors(path string, data []byte, typeErr *yaml.TypeError) error {
+	var doc yaml.Node
+	// the document was already parsed without syntax errors
+	_ = yaml.Unmarshal(data, &doc)
+
+	var keys []yamlKey
+	collectYAMLKeys(&doc, "", &keys)
+
+	errs := make([]error, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		m := yamlErrorPattern.FindStringSubmatch(msg)
+		if m == nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %s", ErrInvalidConfig, path, msg))
+			continue
+		}
+
+		line, _ := strconv.Atoi(m[1])
+		err := fmt.Errorf("%s, line %d: %s", path, line, m[2])
+		if key := findYAMLKey(keys, line, m[2]); key != "" {
+			errs = append(errs, configError(key, err))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrInvalidConfig, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// yamlKey is a key of a YAML document, with its path, such as outputs[0].level, and its value.
+type yamlKey struct {
+	path       string
+	key, value *yaml.Node
+}
+
+// collectYAMLKeys appends the keys of the node and of its children to keys. The path is the path of the node.
+func collectYAMLKeys(node *yaml.Node, path string, keys *[]yamlKey) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectYAMLKeys(child, path, keys)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			*keys = append(*keys, yamlKey{path: keyPath, key: key, value: value})
+			collectYAMLKeys(value, keyPath, keys)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			collectYAMLKeys(child, fmt.Sprintf("%s[%d]", path, i), keys)
+		}
+	}
+}
+
+// findYAMLKey returns the path of the key that the message of a yaml.TypeError, reported at the line, is about,
+// or an empty string if it can't tell. Unknown keys are reported at the line of the key, as in
+// "field levle not found in type pocketlog.config", and invalid values at the line of the value,
+// as in "cannot unmarshal !!str `abc` into int" or "cannot unmarshal !!seq into string".
+func findYAMLKey(keys []yamlKey, line int, msg string) string {
+	if rest, ok := strings.CutPrefix(msg, "field "); ok {
+		name, _, _ := strings.Cut(rest, " ")
+		for _, k := range keys {
+			if k.key.Line == line && k.key.Value == name {
+				return k.path
+			}
+		}
+		return ""
+	}
+
+	kind, value := yaml.ScalarNode, ""
+	switch {
+	case strings.Contains(msg, "!!seq"):
+		kind = yaml.SequenceNode
+	case strings.Contains(msg, "!!map"):
+		kind = yaml.MappingNode
+	default:
+		if _, quoted, ok := strings.Cut(msg, "`"); ok {
+			value, _, _ = strings.Cut(quoted, "`")
+		}
+	}
+
+	for _, k := range keys {
+		if k.value.Line == line && k.value.Kind == kind && (value == "" || k.value.Value == value) {
+			return k.path
+		}
+	}
+	return ""
+}
+
+// applyEnv overrides the configuration with the environment variables that lookup finds.
+func (cfg *config) applyEnv(lookup func(key string) (string, bool)) error {
+	if value, ok := lookup(EnvLevel); ok {
+		if _, err := ParseLevel(value); err != nil {
+			return configError(EnvLevel, err)
+		}
+		cfg.Level = value
+	}
+
+	if value, ok := lookup(EnvFormat); ok {
+		if _, err := parseFormat(value); err != nil {
+			return configError(EnvFormat, err)
+		}
+		cfg.Format = value
+	}
+
+	if value, ok := lookup(EnvOutputs); ok {
+		cfg.Outputs = nil
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path == "" {
+				return configError(EnvOutputs, errors.New("empty output"))
+			}
+			cfg.Outputs = append(cfg.Outputs, outputConfig{Path: path})
+		}
+	}
+
+	return nil
+}
+
+// build validates the configuration, opens its outputs, and returns the logger.
+func (cfg *config) build(opts []Option) (*Logger, error) {
+	threshold := LevelInfo
+	if cfg.Level != "" {
+		var err error
+		if threshold, err = ParseLevel(cfg.Level); err != nil {
+			return nil, configError("level", err)
+		}
+	}
+
+	format, err := parseFormat(cfg.Format)
+	if err != nil {
+		return nil, configError("format", err)
+	}
+
+	namedLevels := make(map[string]Level, len(cfg.NamedLevels))
+	for name, value := range cfg.NamedLevels {
+		if namedLevels[name], err = ParseLevel(value); err != nil {
+			return nil, configError("named_levels."+name, err)
+		}
+	}
+
+	options := []Option{WithFormat(format)}
+	if cfg.Timestamp != "" {
+		options = append(options, WithTimestamp(cfg.Timestamp))
+	}
+	if cfg.UTC {
+		options = append(options, WithUTC())
+	}
+	if cfg.Caller {
+		options = append(options, WithCaller())
+	}
+
+	if cfg.Sampling != nil {
+		interval, err := parseDuration(cfg.Sampling.Interval)
+		if err != nil {
+			return nil, configError("sampling.interval", err)
+		}
+		if cfg.Sampling.First < 0 {
+			return nil, configError("sampling.first", errors.New("must not be negative"))
+		}
+		options = append(options, WithSampling(cfg.Sampling.First, cfg.Sampling.Thereafter, interval))
+	}
+
+	// the caller's options take precedence over the encoding of the configuration
+	encoding := encoder{format: format, timeLayout: cfg.Timestamp}
+	output, outputEncoding := applyEncoding(encoding, opts)
+
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []outputConfig{{Path: "stdout"}}
+	}
+
+	openers := make([]func() (io.Writer, error), len(outputs))
+	thresholds := make([]Level, len(outputs))
+	formats := make([]Format, len(outputs))
+	for i, out := range outputs {
+		key := fmt.Sprintf("outputs[%d].", i)
+
+		if out.Path == "" {
+			return nil, configError(key+"path", errors.New("missing path"))
+		}
+
+		if out.Level != "" {
+			if thresholds[i], err = ParseLevel(out.Level); err != nil {
+				return nil, configError(key+"level", err)
+			}
+		}
+
+		formats[i] = format
+		if out.Format != "" {
+			if formats[i], err = parseFormat(out.Format); err != nil {
+				return nil, configError(key+"format", err)
+			}
+		}
+
+		if openers[i], err = out.opener(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if output != nil {
+		options = append(options, WithSink(newWriterSink(output, LevelDebug, outputEncoding)))
+		return newConfigLogger(threshold, namedLevels, nil, append(options, opts...)), nil
+	}
+
+	// open the outputs once the whole configuration is valid
+	var closers []io.Closer
+	closeAll := func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	}
+
+	sinks := make([]Sink, len(outputs))
+	for i, open := range openers {
+		w, err := open()
+		if err != nil {
+			closeAll()
+			return nil, configError(fmt.Sprintf("outputs[%d].path", i), err)
+		}
+		if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+			closers = append(closers, c)
+		}
+
+		_, enc := applyEncoding(encoder{format: formats[i], timeLayout: cfg.Timestamp}, opts)
+		sinks[i] = newWriterSink(w, thresholds[i], enc)
+	}
+	options = append(options, WithSink(sinks...))
+
+	return newConfigLogger(threshold, namedLevels, closers, append(options, opts...)), nil
+}
+
+// applyEncoding returns the output set by the options, or nil, and the encoder
+// that results from applying the options to enc.
+func applyEncoding(enc encoder, opts []Option) (io.Writer, encoder) {
+	scratch := &Logger{encoder: enc}
+	for _, opt := range opts {
+		opt.apply(scratch)
+	}
+	return scratch.output, scratch.encoder
+}
+
+// newConfigLogger returns a logger with the named levels, that closes the closers when it is closed.
+func newConfigLogger(threshold Level, namedLevels map[string]Level, closers []io.Closer, opts []Option) *Logger {
+	lgr := New(threshold, opts...)
+	for name, lvl := range namedLevels {
+		lgr.SetNamedLevel(name, lvl)
+	}
+	if len(closers) > 0 {
+		lgr.closeOutputs = sync.OnceFunc(func() {
+			for _, c := range closers {
+				if err := c.Close(); err != nil && lgr.onError != nil {
+					lgr.onError(err)
+				}
+			}
+		})
+	}
+
+	return lgr
+}
+
+// opener validates the output, and returns the function that opens it.
+// The key prefixes the names of the keys of the output in errors.
+func (out outputConfig) opener(key string) (func() (io.Writer, error), error) {
+	switch out.Path {
+	case "stdout", "stderr":
+		if out.Rotation != nil {
+			return nil, configError(key+"rotation", fmt.Errorf("%s can't be rotated", out.Path))
+		}
+		w := os.Stdout
+		if out.Path == "stderr" {
+			w = os.Stderr
+		}
+		return func() (io.Writer, error) { return w, nil }, nil
+	}
+
+	if out.Rotation == nil {
+		return func() (io.Writer, error) {
+			return os.OpenFile(out.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		}, nil
+	}
+
+	var rotateOptions []RotateOption
+	if out.Rotation.MaxSize != "" {
+		size, err := parseSize(out.Rotation.MaxSize)
+		if err != nil {
+			return nil, configError(key+"rotation.max_size", err)
+		}
+		rotateOptions = append(rotateOptions, WithMaxSize(size))
+	}
+	if out.Rotation.Interval != "" {
+		interval, err := parseDuration(out.Rotation.Interval)
+		if err != nil {
+			return nil, configError(key+"rotation.interval", err)
+		}
+		rotateOptions = append(rotateOptions, WithRotationInterval(interval))
+	}
+	if out.Rotation.MaxBackups < 0 {
+		return nil, configError(key+"rotation.max_backups", errors.New("must not be negative"))
+	}
+	if out.Rotation.MaxBackups > 0 {
+		rotateOptions = append(rotateOptions, WithMaxBackups(out.Rotation.MaxBackups))
+	}
+	if out.Rotation.Compress {
+		rotateOptions = append(rotateOptions, WithCompression())
+	}
+
+	return func() (io.Writer, error) {
+		return NewRotatingFile(out.Path, rotateOptions...)
+	}, nil
+}
+
+// configError returns an error that wraps ErrInvalidConfig and names the key.
+func configError(key string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, key, err)
+}
+
+// parseFormat parses the name of a format. An empty name stands for FormatText.
+func parseFormat(value string) (Format, error) {
+	switch strings.ToLower(value) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	default:
+		return 0, fmt.Errorf("unknown format %q, expected text, json or logfmt", value)
+	}
+}
+
+// parseDuration parses a positive duration, such as 1m30s.
+func parseDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", value)
+	}
+	return d, nil
+}
+
+// parseSize parses a positive number of bytes, optionally followed by a KB, MB or GB unit, as powers of 1024.
+func parseSize(value string) (int64, error) {
+	number, unit := strings.TrimSpace(value), int64(1)
+	for suffix, multiplier := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(strings.ToUpper(number), suffix) {
+			number, unit = strings.TrimSpace(number[:len(number)-len(suffix)]), multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a positive number of bytes, KB, MB or GB", value)
+	}
+	if n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("size %q is too large", value)
+	}
+	return n * unit, nil
+}
@@ -0,0 +1,239 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"errors"
+	"log/pocketlog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	jsonPath := filepath.Join(dir, "app.json.log")
+
+	config := `
+level: debug
+format: json
+named_levels:
+  db: error
+outputs:
+  - path: ` + logPath + `
+    level: info
+    format: text
+    rotation:
+      max_size: 1MB
+      max_backups: 2
+  - path: ` + jsonPath + `
+sampling:
+  first: 10
+  thereafter: 0
+  interval: 1s
+`
+	configPath := filepath.Join(dir, "pocketlog.yaml")
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatalf("unable to write config: %s", err)
+	}
+
+	testedLogger, err := pocketlog.FromConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Named("db").Warnf("ignored")
+	testedLogger.Infow(infoMessage, "user", 7)
+	testedLogger.Close()
+
+	text, _ := os.ReadFile(logPath)
+	if expected := "INFO:\t" + infoMessage + " user=7\n"; string(text) != expected {
+		t.Errorf("invalid text output, expected %q, got %q", expected, text)
+	}
+
+	json, _ := os.ReadFile(jsonPath)
+	if lines := strings.Split(strings.TrimSpace(string(json)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"fields":{"user":7}`) {
+		t.Errorf("invalid JSON output, got %q", json)
+	}
+}
+
+func TestFromConfig_Env(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+
+	configPath := filepath.Join(dir, "pocketlog.json")
+	if err := os.WriteFile(configPath, []byte(`{"level": "error", "outputs": [{"path": "stderr"}]}`), 0o644); err != nil {
+		t.Fatalf("unable to write config: %s", err)
+	}
+
+	t.Setenv(pocketlog.EnvLevel, "WARN")
+	t.Setenv(pocketlog.EnvFormat, "json")
+	t.Setenv(pocketlog.EnvOutputs, logPath)
+
+	testedLogger, err := pocketlog.FromConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Warnf(errorMessage)
+	testedLogger.Close()
+
+	contents, _ := os.ReadFile(logPath)
+	if expected := `"level":"warn","message":"` + errorMessage + `"}` + "\n"; strings.Count(string(contents), "\n") != 1 || !strings.HasSuffix(string(contents), expected) {
+		t.Errorf("invalid output, expected a single line ending with %q, got %q", expected, contents)
+	}
+}
+
+func TestFromConfig_Options(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+
+	configPath := filepath.Join(dir, "pocketlog.yaml")
+	if err := os.WriteFile(configPath, []byte("timestamp: \"15:04\"\noutputs: [{path: "+logPath+"}]"), 0o644); err != nil {
+		t.Fatalf("unable to write config: %s", err)
+	}
+	clock := pocketlog.WithClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) })
+
+	// the encoding options apply to the outputs of the file
+	testedLogger, err := pocketlog.FromConfig(configPath, clock, pocketlog.WithPrefix("app: "))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testedLogger.Infof(infoMessage)
+	testedLogger.Close()
+
+	contents, _ := os.ReadFile(logPath)
+	if expected := "app: 03:04 INFO:\t" + infoMessage + "\n"; string(contents) != expected {
+		t.Errorf("invalid file contents, expected %q, got %q", expected, contents)
+	}
+
+	// the output replaces those of the file
+	var buf bytes.Buffer
+	testedLogger, err = pocketlog.FromConfig(configPath, clock, pocketlog.WithOutput(&buf), pocketlog.WithFormat(pocketlog.FormatJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testedLogger.Infof(infoMessage)
+	testedLogger.Close()
+
+	if expected := `{"time":"2024-01-02T03:04:05Z","level":"info","message":"` + infoMessage + `"}` + "\n"; buf.String() != expected {
+		t.Errorf("invalid output, expected %q, got %q", expected, buf.String())
+	}
+	if contents, _ = os.ReadFile(logPath); strings.Count(string(contents), "\n") != 1 {
+		t.Errorf("expected the file to be left alone, got %q", contents)
+	}
+}
+
+func TestFromConfig_Errors(t *testing.T) {
+	tt := map[string]struct {
+		file     string
+		config   string
+		env      map[string]string
+		expected string
+	}{
+		"unknown key": {
+			file:     "c.yaml",
+			config:   "levle: info",
+			expected: "field levle not found",
+		},
+		"unknown nested key": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: stdout, levle: info}]",
+			expected: "invalid config: outputs[0].levle: ",
+		},
+		"YAML type": {
+			file:     "c.yaml",
+			config:   "level: info\nsampling:\n  first: abc\n",
+			expected: "invalid config: sampling.first: ",
+		},
+		"YAML sequence": {
+			file:     "c.yaml",
+			config:   "level: [info]",
+			expected: "invalid config: level: ",
+		},
+		"YAML flow mapping": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: app.log, rotation: {max_size: 1MB, max_backups: many}}]",
+			expected: "invalid config: outputs[0].rotation.max_backups: ",
+		},
+		"unknown JSON key": {
+			file:     "c.json",
+			config:   `{"levle": "info"}`,
+			expected: `unknown field "levle"`,
+		},
+		"level": {
+			file:     "c.yaml",
+			config:   "level: loud",
+			expected: "invalid config: level: unknown level",
+		},
+		"named level": {
+			file:     "c.yaml",
+			config:   "named_levels: {db: loud}",
+			expected: "invalid config: named_levels.db: unknown level",
+		},
+		"output format": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: stdout}, {path: stderr, format: xml}]",
+			expected: "invalid config: outputs[1].format: unknown format",
+		},
+		"rotated stdout": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: stdout, rotation: {max_size: 1MB}}]",
+			expected: "invalid config: outputs[0].rotation: stdout can't be rotated",
+		},
+		"max size": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: app.log, rotation: {max_size: lots}}]",
+			expected: "invalid config: outputs[0].rotation.max_size: invalid size",
+		},
+		"max size overflow": {
+			file:     "c.yaml",
+			config:   "outputs: [{path: app.log, rotation: {max_size: 9000000000000GB}}]",
+			expected: "invalid config: outputs[0].rotation.max_size: size \"9000000000000GB\" is too large",
+		},
+		"sampling interval": {
+			file:     "c.json",
+			config:   `{"sampling": {"first": 1, "interval": "-1s"}}`,
+			expected: "invalid config: sampling.interval: ",
+		},
+		"extension": {
+			file:     "c.toml",
+			config:   `level = "info"`,
+			expected: `invalid config: unsupported file extension ".toml"`,
+		},
+		"env level": {
+			file:     "c.yaml",
+			env:      map[string]string{pocketlog.EnvLevel: "loud"},
+			expected: "invalid config: POCKETLOG_LEVEL: unknown level",
+		},
+		"env format": {
+			file:     "c.yaml",
+			env:      map[string]string{pocketlog.EnvFormat: "xml"},
+			expected: "invalid config: POCKETLOG_FORMAT: unknown format",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, []byte(tc.config), 0o644); err != nil {
+				t.Fatalf("unable to write config: %s", err)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := pocketlog.FromConfig(path)
+			if !errors.Is(err, pocketlog.ErrInvalidConfig) {
+				t.Fatalf("expected ErrInvalidConfig, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error containing %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
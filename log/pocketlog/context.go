@@ -0,0 +1,147 @@
+package pocketlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// loggerContextKey and fieldsContextKey are the keys of the values stored in contexts by this package.
+type (
+	loggerContextKey struct{}
+	fieldsContextKey struct{}
+)
+
+// defaultLogger is returned by FromContext for contexts that hold no logger.
+var defaultLogger = New(LevelInfo)
+
+// NewContext returns a copy of ctx that holds lgr.
+func NewContext(ctx context.Context, lgr *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, lgr)
+}
+
+// FromContext returns the logger held by ctx. If there is none, it returns a logger
+// that prints entries at or above LevelInfo to Stdout.
+func FromContext(ctx context.Context) *Logger {
+	if lgr, ok := ctx.Value(loggerContextKey{}).(*Logger); ok {
+		return lgr
+	}
+	return defaultLogger
+}
+
+// ContextWithFields returns a copy of ctx that holds the given key/value pairs,
+// after the ones already held by ctx. The *Ctx methods of loggers add them to every entry,
+// after the fields of the logger.
+func ContextWithFields(ctx context.Context, keysAndValues ...any) context.Context {
+	fields := FieldsFromContext(ctx)
+	// cap the slice so that contexts derived from the same parent never share an appended array
+	fields = append(fields[:len(fields):len(fields)], fieldsFromPairs(keysAndValues)...)
+	return context.WithValue(ctx, fieldsContextKey{}, fields)
+}
+
+// FieldsFromContext returns the fields held by ctx. The returned slice must not be modified.
+func FieldsFromContext(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsContextKey{}).([]Field)
+	return fields
+}
+
+// contextFields returns the fields held by ctx, followed by those returned by the extractors of the logger.
+func (l *Logger) contextFields(ctx context.Context) []Field {
+	fields := FieldsFromContext(ctx)
+	if len(l.extractors) == 0 {
+		return fields
+	}
+
+	fields = fields[:len(fields):len(fields)]
+	for _, extract := range l.extractors {
+		fields = append(fields, extract(ctx)...)
+	}
+	return fields
+}
+
+// DebugfCtx formats and prints a message with the fields of ctx if the log level is debug or higher.
+func (l *Logger) DebugfCtx(ctx context.Context, format string, args ...any) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+
+	l.logf(LevelDebug, format, fmt.Sprintf(format, args...), l.contextFields(ctx), nil)
+}
+
+// InfofCtx formats and prints a message with the fields of ctx if the log level is info or higher.
+func (l *Logger) InfofCtx(ctx context.Context, format string, args ...any) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+
+	l.logf(LevelInfo, format, fmt.Sprintf(format, args...), l.contextFields(ctx), nil)
+}
+
+// WarnfCtx formats and prints a message with the fields of ctx if the log level is warn or higher.
+func (l *Logger) WarnfCtx(ctx context.Context, format string, args ...any) {
+	if !l.Enabled(LevelWarn) {
+		return
+	}
+
+	l.logf(LevelWarn, format, fmt.Sprintf(format, args...), l.contextFields(ctx), nil)
+}
+
+// ErrorfCtx formats and prints a message with the fields of ctx if the log level is error or higher.
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, args ...any) {
+	if !l.Enabled(LevelError) {
+		return
+	}
+
+	l.logf(LevelError, format, fmt.Sprintf(format, args...), l.contextFields(ctx), nil)
+}
+
+// FatalfCtx formats and prints a message with the fields of ctx whatever the log level,
+// waits for it to be written, then calls the exit function with status 1.
+func (l *Logger) FatalfCtx(ctx context.Context, format string, args ...any) {
+	l.logf(LevelFatal, format, fmt.Sprintf(format, args...), l.contextFields(ctx), nil)
+	l.Flush()
+	l.exit(1)
+}
+
+// DebugwCtx prints a message with the fields of ctx, followed by key/value pairs, if the log level is debug or higher.
+func (l *Logger) DebugwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+
+	l.logf(LevelDebug, msg, msg, l.contextFields(ctx), keysAndValues)
+}
+
+// InfowCtx prints a message with the fields of ctx, followed by key/value pairs, if the log level is info or higher.
+func (l *Logger) InfowCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+
+	l.logf(LevelInfo, msg, msg, l.contextFields(ctx), keysAndValues)
+}
+
+// WarnwCtx prints a message with the fields of ctx, followed by key/value pairs, if the log level is warn or higher.
+func (l *Logger) WarnwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelWarn) {
+		return
+	}
+
+	l.logf(LevelWarn, msg, msg, l.contextFields(ctx), keysAndValues)
+}
+
+// ErrorwCtx prints a message with the fields of ctx, followed by key/value pairs, if the log level is error or higher.
+func (l *Logger) ErrorwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelError) {
+		return
+	}
+
+	l.logf(LevelError, msg, msg, l.contextFields(ctx), keysAndValues)
+}
+
+// FatalwCtx prints a message with the fields of ctx, followed by key/value pairs, whatever the log level,
+// waits for it to be written, then calls the exit function with status 1.
+func (l *Logger) FatalwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logf(LevelFatal, msg, msg, l.contextFields(ctx), keysAndValues)
+	l.Flush()
+	l.exit(1)
+}
@@ -0,0 +1,74 @@
+package pocketlog_test
+
+import (
+	"context"
+	"fmt"
+	"log/pocketlog"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+// traceIDKey is the context key used by a fake tracing library.
+type traceIDKey struct{}
+
+func TestFromContext(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelDebug)
+
+	if got := pocketlog.FromContext(pocketlog.NewContext(context.Background(), lgr)); got != lgr {
+		t.Errorf("expected the logger held by the context, got %p", got)
+	}
+
+	if got := pocketlog.FromContext(context.Background()); got == nil || got.Level() != pocketlog.LevelInfo {
+		t.Errorf("expected a default logger at level info, got %v", got)
+	}
+}
+
+func TestLogger_Ctx(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithCaller(),
+		pocketlog.WithExitFunc(func(int) {}),
+		pocketlog.WithContextExtractor(func(ctx context.Context) []pocketlog.Field {
+			if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+				return []pocketlog.Field{pocketlog.Any("trace_id", id)}
+			}
+			return nil
+		})).With("service", "api")
+
+	parent := pocketlog.ContextWithFields(context.Background(), "request_id", "abc-123")
+	ctx := pocketlog.ContextWithFields(parent, "user_id", 42)
+	// a sibling context must not see the fields of ctx
+	sibling := pocketlog.ContextWithFields(parent, "user_id", 7)
+	ctx = context.WithValue(ctx, traceIDKey{}, "t-1")
+
+	_, _, line, _ := runtime.Caller(0)
+	testedLogger.InfofCtx(ctx, "%s", infoMessage)
+	testedLogger.WarnwCtx(sibling, debugMessage, "attempt", 2)
+	testedLogger.FatalwCtx(context.Background(), errorMessage)
+	slog.New(pocketlog.NewSlogHandler(testedLogger)).ErrorContext(ctx, errorMessage, "code", 7)
+
+	expected := fmt.Sprintf("pocketlog/context_test.go:%d: INFO:\t%s service=api request_id=abc-123 user_id=42 trace_id=t-1\n", line+1, infoMessage) +
+		fmt.Sprintf("pocketlog/context_test.go:%d: WARN:\t%s service=api request_id=abc-123 user_id=7 attempt=2\n", line+2, debugMessage) +
+		fmt.Sprintf("pocketlog/context_test.go:%d: FATAL:\t%s service=api\n", line+3, errorMessage) +
+		fmt.Sprintf("pocketlog/context_test.go:%d: ERROR:\t%s service=api request_id=abc-123 user_id=42 trace_id=t-1 code=7\n", line+4, errorMessage)
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_Ctx_Threshold(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelError, pocketlog.WithOutput(tw))
+
+	ctx := pocketlog.ContextWithFields(context.Background(), "request_id", "abc-123")
+	testedLogger.DebugfCtx(ctx, debugMessage)
+	testedLogger.InfowCtx(ctx, infoMessage)
+	testedLogger.WarnfCtx(ctx, infoMessage)
+	testedLogger.ErrorfCtx(ctx, errorMessage)
+
+	expected := "ERROR:\t" + errorMessage + " request_id=abc-123\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
@@ -0,0 +1,23 @@
+package pocketlog
+
+import "time"
+
+// Entry is a single log record, as handed to sinks.
+type Entry struct {
+	// Time is when the entry was logged. It is zero for slog records without a time.
+	Time time.Time
+	// Level is the level the entry was logged at.
+	Level Level
+	// Name is the name of the logger, or an empty string for loggers that weren't named.
+	Name string
+	// Message is the formatted message.
+	Message string
+	// Fields holds the fields of the logger, followed by the fields of the call.
+	Fields []Field
+	// PC is the program counter of the code that logged the entry, or zero if unknown.
+	// It is only set for loggers created with WithCaller.
+	PC uintptr
+
+	// template is the format string or the message that the message was built from.
+	template string
+}
@@ -0,0 +1,131 @@
+package pocketlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxErrorDepth limits how deep Err follows wrapped errors, in case an error wraps itself.
+const maxErrorDepth = 32
+
+// maxStackDepth is the number of frames of the stack traces captured by Err.
+const maxStackDepth = 32
+
+// ErrorChain describes an error and the errors it wraps, as returned by its Unwrap() error
+// or Unwrap() []error method. It's the value of the error_chain field added by Err.
+// Text entries print it as "message (type) > wrapped", with the errors of errors.Join in brackets.
+type ErrorChain struct {
+	Message string       `json:"message"`
+	Type    string       `json:"type"`
+	Wrapped []ErrorChain `json:"wrapped,omitempty"`
+}
+
+// newErrorChain returns the description of err and of the errors it wraps, up to depth levels deep.
+func newErrorChain(err error, depth int) ErrorChain {
+	chain := ErrorChain{Message: nilSafeText(err, err.Error), Type: fmt.Sprintf("%T", err)}
+	if depth == 0 || isNilPointer(err) {
+		// the Unwrap method of a nil pointer may panic too
+		return chain
+	}
+
+	var wrapped []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	case interface{ Unwrap() error }:
+		wrapped = []error{u.Unwrap()}
+	}
+
+	for _, w := range wrapped {
+		if w != nil {
+			chain.Wrapped = append(chain.Wrapped, newErrorChain(w, depth-1))
+		}
+	}
+	return chain
+}
+
+// String implements the fmt.Stringer interface.
+func (ec ErrorChain) String() string {
+	var sb strings.Builder
+	ec.writeTo(&sb)
+	return sb.String()
+}
+
+// writeTo writes the text representation of the chain.
+func (ec ErrorChain) writeTo(sb *strings.Builder) {
+	sb.WriteString(ec.Message)
+	sb.WriteString(" (")
+	sb.WriteString(ec.Type)
+	sb.WriteByte(')')
+
+	switch len(ec.Wrapped) {
+	case 0:
+	case 1:
+		sb.WriteString(" > ")
+		ec.Wrapped[0].writeTo(sb)
+	default:
+		sb.WriteString(" > [")
+		for i, w := range ec.Wrapped {
+			if i > 0 {
+				sb.WriteString(" | ")
+			}
+			w.writeTo(sb)
+		}
+		sb.WriteByte(']')
+	}
+}
+
+// StackTrace is a list of frames, from the innermost call, as in "main.run (app/main.go:12)".
+// It's the value of the stack field added by Err when the logger was created WithErrorStack.
+// Text entries print its frames separated by commas.
+type StackTrace []string
+
+// captureStack returns the stack trace of the caller of the function that calls it.
+func captureStack() StackTrace {
+	var pcs [maxStackDepth]uintptr
+	// skip runtime.Callers, captureStack, and its caller
+	n := runtime.Callers(3, pcs[:])
+
+	stack := make(StackTrace, 0, n)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		dir, file := filepath.Split(frame.File)
+		stack = append(stack, frame.Function+" ("+filepath.Base(dir)+"/"+file+":"+strconv.Itoa(frame.Line)+")")
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// String implements the fmt.Stringer interface.
+func (st StackTrace) String() string {
+	return strings.Join(st, ", ")
+}
+
+// Err returns a child logger that adds err to every entry it prints, as structured data:
+// the error field holds its message, and the error_chain field, only present for errors that wrap
+// others, holds an ErrorChain. Loggers created WithErrorStack also add the stack trace of the call
+// to Err in the stack field. Err returns the logger itself for a nil error. An error that holds
+// a nil pointer has the message "<nil>" when its Error method panics.
+//
+//	lgr.Err(err).Errorf("unable to save user %d", id)
+func (l *Logger) Err(err error) *Logger {
+	if err == nil {
+		return l
+	}
+
+	fields := []Field{String("error", nilSafeText(err, err.Error))}
+	if chain := newErrorChain(err, maxErrorDepth); len(chain.Wrapped) > 0 {
+		fields = append(fields, Any("error_chain", chain))
+	}
+	if l.errorStack {
+		fields = append(fields, Any("stack", captureStack()))
+	}
+
+	return l.WithFields(fields...)
+}
@@ -0,0 +1,113 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogtest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// errNotFound is the root cause of the errors logged in these tests.
+var errNotFound = errors.New("not found")
+
+func TestLogger_Err(t *testing.T) {
+	err := fmt.Errorf("saving user: %w", errors.Join(errNotFound, fmt.Errorf("retry: %w", errNotFound)))
+
+	tt := map[string]struct {
+		format   pocketlog.Format
+		expected string
+	}{
+		"text": {
+			format: pocketlog.FormatText,
+			expected: "ERROR:\t" + errorMessage + ` error="saving user: not found\nretry: not found"` +
+				` error_chain="saving user: not found\nretry: not found (*fmt.wrapError) > not found\nretry: not found (*errors.joinError)` +
+				` > [not found (*errors.errorString) | retry: not found (*fmt.wrapError) > not found (*errors.errorString)]"` + "\n",
+		},
+		"json": {
+			format: pocketlog.FormatJSON,
+			expected: `"fields":{"error":"saving user: not found\nretry: not found",` +
+				`"error_chain":{"message":"saving user: not found\nretry: not found","type":"*fmt.wrapError","wrapped":[` +
+				`{"message":"not found\nretry: not found","type":"*errors.joinError","wrapped":[` +
+				`{"message":"not found","type":"*errors.errorString"},` +
+				`{"message":"retry: not found","type":"*fmt.wrapError","wrapped":[{"message":"not found","type":"*errors.errorString"}]}]}]}}}` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(tc.format))
+
+			testedLogger.Err(err).Errorf(errorMessage)
+
+			if !strings.HasSuffix(tw.contents, tc.expected) {
+				t.Errorf("invalid contents, expected suffix %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_Err_Simple(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	lgr.Err(errNotFound).Warnf("lookup failed")
+	lgr.Err(nil).Infof("no error")
+
+	entries := rec.Entries()
+	expected := []pocketlog.Field{pocketlog.String("error", "not found")}
+	if len(entries) != 2 || !reflect.DeepEqual(entries[0].Fields, expected) || len(entries[1].Fields) != 0 {
+		t.Errorf("expected an error field without chain, then no field, got %v", entries)
+	}
+}
+
+func TestLogger_Err_NilPointer(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	var err *nilPointerError
+	lgr.Err(err).Errorf("typed nil")
+	lgr.Err(fmt.Errorf("wrapped: %w", err)).Errorf("wrapped typed nil")
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+
+	expected := []pocketlog.Field{pocketlog.String("error", "<nil>")}
+	if !reflect.DeepEqual(entries[0].Fields, expected) {
+		t.Errorf("expected %v, got %v", expected, entries[0].Fields)
+	}
+
+	chain := pocketlog.ErrorChain{Message: "wrapped: <nil>", Type: "*fmt.wrapError", Wrapped: []pocketlog.ErrorChain{
+		{Message: "<nil>", Type: "*pocketlog_test.nilPointerError"},
+	}}
+	expected = []pocketlog.Field{pocketlog.String("error", "wrapped: <nil>"), pocketlog.Any("error_chain", chain)}
+	if !reflect.DeepEqual(entries[1].Fields, expected) {
+		t.Errorf("expected %v, got %v", expected, entries[1].Fields)
+	}
+}
+
+func TestLogger_Err_Stack(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatJSON),
+		pocketlog.WithErrorStack())
+
+	testedLogger.Err(errNotFound).Errorf(errorMessage)
+
+	var entry struct {
+		Fields struct {
+			Stack []string `json:"stack"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(tw.contents), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %s", tw.contents, err)
+	}
+
+	expected := "log/pocketlog_test.TestLogger_Err_Stack (pocketlog/errchain_test.go:"
+	if len(entry.Fields.Stack) == 0 || !strings.HasPrefix(entry.Fields.Stack[0], expected) {
+		t.Errorf("expected a stack starting with %q, got %v", expected, entry.Fields.Stack)
+	}
+}
@@ -0,0 +1,9 @@
+package pocketlog
+
+// pocketlogError defines a sentinel error
+type pocketlogError string
+
+// Error is the implementation of the error interface by pocketlogError
+func (e pocketlogError) Error() string {
+	return string(e)
+}
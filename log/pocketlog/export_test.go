@@ -0,0 +1,9 @@
+package pocketlog
+
+// QueueWaiting returns the number of callers waiting for room in the queue of an asynchronous logger.
+func QueueWaiting(l *Logger) int {
+	l.queue.mu.Lock()
+	defer l.queue.mu.Unlock()
+
+	return l.queue.waiting
+}
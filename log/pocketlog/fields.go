@@ -0,0 +1,281 @@
+package pocketlog
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// badKey is the key used for values that were not preceded by a string key.
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to an entry. Fields are built with the typed constructors,
+// such as String and Int, which hold their value without allocating, or with Any.
+// Their value is read with the Any method.
+type Field struct {
+	Key string
+
+	// value holds the value of fields built with Any.
+	value any
+
+	// kind tells where typed fields hold their value: str for strings, num for the others.
+	kind fieldKind
+	num  uint64
+	str  string
+}
+
+// fieldKind is the type of the value of a typed field.
+type fieldKind byte
+
+const (
+	// kindAny is the kind of fields whose value is in value.
+	kindAny fieldKind = iota
+	kindString
+	kindInt
+	kindInt64
+	kindUint64
+	kindFloat64
+	kindBool
+	kindDuration
+)
+
+// String returns a field with a string value.
+func String(key, value string) Field {
+	return Field{Key: key, kind: kindString, str: value}
+}
+
+// Int returns a field with an int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: kindInt, num: uint64(value)}
+}
+
+// Int64 returns a field with an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: kindInt64, num: uint64(value)}
+}
+
+// Uint64 returns a field with a uint64 value.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, kind: kindUint64, num: value}
+}
+
+// Float64 returns a field with a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: kindFloat64, num: math.Float64bits(value)}
+}
+
+// Bool returns a field with a bool value.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: kindBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration returns a field with a time.Duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, num: uint64(value)}
+}
+
+// Any returns a field with a value of any type, which is printed like the values of key/value pairs.
+func Any(key string, value any) Field {
+	return Field{Key: key, value: value}
+}
+
+// Any returns the value of the field, whichever way it was built.
+func (f Field) Any() any {
+	switch f.kind {
+	case kindString:
+		return f.str
+	case kindInt:
+		return int(f.num)
+	case kindInt64:
+		return int64(f.num)
+	case kindUint64:
+		return f.num
+	case kindFloat64:
+		return math.Float64frombits(f.num)
+	case kindBool:
+		return f.num == 1
+	case kindDuration:
+		return time.Duration(f.num)
+	default:
+		return f.value
+	}
+}
+
+// textValue returns the value of the field if it is made of text.
+func (f Field) textValue() (string, bool) {
+	if f.kind == kindString {
+		return f.str, true
+	}
+	// the Value of other typed fields is nil
+	return textValue(f.value)
+}
+
+// text returns the text representation of the value of the field.
+func (f Field) text() string {
+	if f.kind == kindString {
+		return f.str
+	}
+	return formatValue(f.Any())
+}
+
+// fieldsFromPairs turns alternating keys and values into fields, keeping their order.
+// A non-string key, or a key without a value, is kept as the value of a !BADKEY field.
+func fieldsFromPairs(keysAndValues []any) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	return appendPairs(make([]Field, 0, (len(keysAndValues)+1)/2), keysAndValues)
+}
+
+// appendPairs appends the fields of alternating keys and values to fields, as fieldsFromPairs does.
+func appendPairs(fields []Field, keysAndValues []any) []Field {
+	for i := 0; i < len(keysAndValues); {
+		key, ok := keysAndValues[i].(string)
+		if !ok || i+1 == len(keysAndValues) {
+			fields = append(fields, Field{Key: badKey, value: keysAndValues[i]})
+			i++
+			continue
+		}
+
+		fields = append(fields, Field{Key: key, value: keysAndValues[i+1]})
+		i += 2
+	}
+
+	return fields
+}
+
+// textValue returns the text of values made of text: strings, byte slices, errors and fmt.Stringers.
+func textValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case error:
+		return nilSafeText(v, v.Error), true
+	case fmt.Stringer:
+		return nilSafeText(v, v.String), true
+	default:
+		return "", false
+	}
+}
+
+// nilSafeText returns the result of text, a method of v such as Error or String. As fmt does,
+// it returns "<nil>" when v is a nil pointer whose method panics.
+func nilSafeText(v any, text func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if isNilPointer(v) {
+				s = "<nil>"
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	return text()
+}
+
+// isNilPointer reports whether v holds a nil pointer.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// replaceValues returns the fields, with the value of each field replaced by the value returned
+// by replace when it returns true. The fields are copied before the first change,
+// as they may be shared with the logger.
+func replaceValues(fields []Field, replace func(f Field) (any, bool)) []Field {
+	copied := false
+	for i, f := range fields {
+		value, ok := replace(f)
+		if !ok {
+			continue
+		}
+
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		// drop the typed value, if any
+		fields[i] = Field{Key: f.Key, value: value}
+	}
+
+	return fields
+}
+
+// appendTextFields appends each field as a space-separated key=value pair.
+func appendTextFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = appendTextValue(buf, f.Key)
+		buf = append(buf, '=')
+		buf = appendTextFieldValue(buf, f)
+	}
+	return buf
+}
+
+// appendTextFieldValue appends the text representation of the value of the field, quoted if needed.
+// Typed values are appended without being converted to interfaces.
+func appendTextFieldValue(buf []byte, f Field) []byte {
+	switch f.kind {
+	case kindString:
+		return appendTextValue(buf, f.str)
+	case kindInt, kindInt64:
+		return strconv.AppendInt(buf, int64(f.num), 10)
+	case kindUint64:
+		return strconv.AppendUint(buf, f.num, 10)
+	case kindFloat64:
+		return strconv.AppendFloat(buf, math.Float64frombits(f.num), 'g', -1, 64)
+	case kindBool:
+		return strconv.AppendBool(buf, f.num == 1)
+	case kindDuration:
+		return append(buf, time.Duration(f.num).String()...)
+	default:
+		return appendTextValue(buf, formatValue(f.Any()))
+	}
+}
+
+// formatValue returns the text representation of a field value.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case error:
+		return nilSafeText(v, v.Error)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// appendTextValue appends s, quoted if it would otherwise be ambiguous to a parser.
+func appendTextValue(buf []byte, s string) []byte {
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// needsQuoting reports whether s is empty or contains spaces, quotes, equal signs,
+// or characters that aren't printable.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r == utf8.RuneError || r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"io"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogtest"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_TypedFields(t *testing.T) {
+	fields := []pocketlog.Field{
+		pocketlog.String("path", "/a b"),
+		pocketlog.Int("status", -200),
+		pocketlog.Int64("size", 1<<40),
+		pocketlog.Uint64("id", math.MaxUint64),
+		pocketlog.Float64("ratio", 0.5),
+		pocketlog.Float64("tiny", 1e-9),
+		pocketlog.Bool("cached", true),
+		pocketlog.Duration("took", 1500*time.Millisecond),
+		pocketlog.Any("err", errors.New("boom")),
+	}
+
+	tt := map[string]struct {
+		format   pocketlog.Format
+		expected string
+	}{
+		"text": {
+			format: pocketlog.FormatText,
+			expected: `INFO:` + "\t" + infoMessage + ` path="/a b" status=-200 size=1099511627776 id=18446744073709551615` +
+				` ratio=0.5 tiny=1e-09 cached=true took=1.5s err=boom` + "\n",
+		},
+		"json": {
+			format: pocketlog.FormatJSON,
+			expected: `"fields":{"path":"/a b","status":-200,"size":1099511627776,"id":18446744073709551615,` +
+				`"ratio":0.5,"tiny":1e-9,"cached":true,"took":1500000000,"err":"boom"}}` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(tc.format))
+
+			testedLogger.Debug(debugMessage, fields...)
+			testedLogger.Info(infoMessage, fields...)
+
+			if !strings.HasSuffix(tw.contents, tc.expected) || strings.Count(tw.contents, "\n") != 1 {
+				t.Errorf("invalid contents, expected a single line ending with %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+// nilPointerError is an error whose Error method panics on nil pointers.
+type nilPointerError struct{ msg string }
+
+func (e *nilPointerError) Error() string { return e.msg }
+
+// nilPointerStringer is a fmt.Stringer whose String method panics on nil pointers.
+type nilPointerStringer struct{ name string }
+
+func (s *nilPointerStringer) String() string { return s.name }
+
+func TestLogger_TypedNil(t *testing.T) {
+	tt := map[string]struct {
+		opts     []pocketlog.Option
+		expected string
+	}{
+		"text": {
+			expected: "INFO:\t" + infoMessage + " err=<nil> who=<nil>\n",
+		},
+		"json": {
+			opts:     []pocketlog.Option{pocketlog.WithFormat(pocketlog.FormatJSON)},
+			expected: `"fields":{"err":"\u003cnil\u003e","who":null}}` + "\n",
+		},
+		"logfmt": {
+			opts:     []pocketlog.Option{pocketlog.WithFormat(pocketlog.FormatLogfmt)},
+			expected: "msg=\"" + infoMessage + "\" err=<nil> who=<nil>\n",
+		},
+		"redactor": {
+			opts:     []pocketlog.Option{pocketlog.WithRedactor(pocketlog.NewRegexRedactor(regexp.MustCompile("secret"), "***"))},
+			expected: "INFO:\t" + infoMessage + " err=<nil> who=<nil>\n",
+		},
+		"max length": {
+			opts:     []pocketlog.Option{pocketlog.WithMaxMessageLength(100)},
+			expected: "INFO:\t" + infoMessage + " err=<nil> who=<nil>\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, append(tc.opts, pocketlog.WithOutput(tw))...)
+
+			var err *nilPointerError
+			var who *nilPointerStringer
+			testedLogger.Infow(infoMessage, "err", err, "who", who)
+
+			if !strings.HasSuffix(tw.contents, tc.expected) {
+				t.Errorf("invalid contents, expected a line ending with %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestField_Any(t *testing.T) {
+	tt := map[string]struct {
+		field    pocketlog.Field
+		expected any
+	}{
+		"string":   {field: pocketlog.String("k", "v"), expected: "v"},
+		"int":      {field: pocketlog.Int("k", -1), expected: -1},
+		"int64":    {field: pocketlog.Int64("k", -1), expected: int64(-1)},
+		"uint64":   {field: pocketlog.Uint64("k", 1), expected: uint64(1)},
+		"float64":  {field: pocketlog.Float64("k", 0.5), expected: 0.5},
+		"bool":     {field: pocketlog.Bool("k", false), expected: false},
+		"duration": {field: pocketlog.Duration("k", time.Second), expected: time.Second},
+		"any":      {field: pocketlog.Any("k", []int{1}), expected: []int{1}},
+		"literal":  {field: pocketlog.Any("k", "v"), expected: "v"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			lgr, rec := pocketlogtest.NewLogger(t)
+			lgr.Info(infoMessage, tc.field)
+
+			if rec.Count(pocketlogtest.HasField("k", tc.expected)) != 1 {
+				t.Errorf("expected a field of value %#v, got %#v", tc.expected, rec.Entries()[0].Fields[0].Any())
+			}
+		})
+	}
+}
+
+func TestLogger_WithFields(t *testing.T) {
+	tw := &testWriter{}
+	parent := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw)).With("service", "users")
+
+	parent.WithFields(pocketlog.Int("shard", 1)).Info(infoMessage, pocketlog.Bool("ok", true))
+	parent.WithFields(pocketlog.Int("shard", 2)).Error(errorMessage)
+
+	expected := "INFO:\t" + infoMessage + " service=users shard=1 ok=true\n" + "ERROR:\t" + errorMessage + " service=users shard=2\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+// raceEnabled tells whether the tests run with the race detector.
+var raceEnabled bool
+
+func TestLogger_Info_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard), pocketlog.WithFormat(pocketlog.FormatJSON))
+	path := "/users"
+
+	tt := map[string]func(){
+		"disabled": func() { lgr.Debug(debugMessage, pocketlog.String("path", path), pocketlog.Int("status", 404)) },
+		"enabled":  func() { lgr.Info(infoMessage, pocketlog.String("path", path), pocketlog.Int("status", 404)) },
+	}
+
+	for name, log := range tt {
+		t.Run(name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, log); allocs != 0 {
+				t.Errorf("expected no allocations, got %v", allocs)
+			}
+		})
+	}
+}
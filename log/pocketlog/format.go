@@ -0,0 +1,267 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Format defines how entries are encoded before they are written to the output.
+type Format byte
+
+const (
+	// FormatText prints entries as a level prefix followed by the message and key=value pairs.
+	FormatText Format = iota
+	// FormatJSON prints each entry as a single-line JSON object.
+	FormatJSON
+	// FormatLogfmt prints each entry as logfmt key=value pairs, starting with time, level, logger, caller and msg.
+	FormatLogfmt
+)
+
+// encoder holds the settings used to turn entries into lines.
+type encoder struct {
+	format Format
+	// timeLayout is the layout of the timestamp of text entries. Text entries have no timestamp when it is empty.
+	timeLayout string
+	// prefix starts every text entry.
+	prefix string
+	// colorMode is the requested color mode, and color tells whether it resolved to colors for the output.
+	colorMode ColorMode
+	color     bool
+}
+
+// encode appends the line that represents the entry in the format, including its trailing newline, to buf.
+func (enc encoder) encode(buf []byte, e Entry) []byte {
+	switch enc.format {
+	case FormatJSON:
+		return appendJSON(buf, e)
+	case FormatLogfmt:
+		return appendLogfmt(buf, e)
+	default:
+		return enc.appendText(buf, e)
+	}
+}
+
+// appendText appends the plain-text representation of the entry to buf.
+// Decorations come first, in the same order as with the standard log package:
+// the prefix, the timestamp followed by a space, and the caller followed by a colon and a space.
+func (enc encoder) appendText(buf []byte, e Entry) []byte {
+	buf = append(buf, enc.prefix...)
+	if enc.timeLayout != "" && !e.Time.IsZero() {
+		buf = e.Time.AppendFormat(buf, enc.timeLayout)
+		buf = append(buf, ' ')
+	}
+	if e.PC != 0 {
+		buf = appendCaller(buf, e.PC)
+		buf = append(buf, ": "...)
+	}
+
+	if enc.color {
+		// keep the tab out of the colored text
+		prefix := e.Level.prefix()
+		buf = append(buf, e.Level.color()...)
+		buf = append(buf, prefix[:len(prefix)-1]...)
+		buf = append(buf, colorReset...)
+		buf = append(buf, prefix[len(prefix)-1:]...)
+	} else {
+		buf = append(buf, e.Level.prefix()...)
+	}
+	if e.Name != "" {
+		buf = append(buf, e.Name...)
+		buf = append(buf, ": "...)
+	}
+	buf = append(buf, e.Message...)
+	buf = appendTextFields(buf, e.Fields)
+	return append(buf, '\n')
+}
+
+// appendJSON appends the JSON representation of the entry to buf, as a single-line object
+// with the time, level, logger, caller, message and fields keys. The time, logger, caller and fields
+// are omitted when the entry has none. Strings are escaped as with encoding/json.
+func appendJSON(buf []byte, e Entry) []byte {
+	buf = append(buf, '{')
+	// entries that come from slog records may have no time
+	if !e.Time.IsZero() {
+		buf = append(buf, `"time":"`...)
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, `",`...)
+	}
+
+	buf = append(buf, `"level":`...)
+	buf = appendJSONString(buf, e.Level.String())
+	if e.Name != "" {
+		buf = append(buf, `,"logger":`...)
+		buf = appendJSONString(buf, e.Name)
+	}
+	if e.PC != 0 {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONString(buf, string(appendCaller(nil, e.PC)))
+	}
+	buf = append(buf, `,"message":`...)
+	buf = appendJSONString(buf, e.Message)
+
+	if len(e.Fields) > 0 {
+		buf = append(buf, `,"fields":{`...)
+		for i, f := range e.Fields {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, f.Key)
+			buf = append(buf, ':')
+			buf = appendJSONFieldValue(buf, f)
+		}
+		buf = append(buf, '}')
+	}
+
+	return append(buf, "}\n"...)
+}
+
+// appendJSONFieldValue appends the JSON encoding of the value of the field.
+// Typed values are appended without being converted to interfaces.
+func appendJSONFieldValue(buf []byte, f Field) []byte {
+	switch f.kind {
+	case kindString:
+		return appendJSONString(buf, f.str)
+	case kindInt, kindInt64, kindDuration:
+		return strconv.AppendInt(buf, int64(f.num), 10)
+	case kindUint64:
+		return strconv.AppendUint(buf, f.num, 10)
+	case kindFloat64:
+		return appendJSONFloat(buf, math.Float64frombits(f.num))
+	case kindBool:
+		return strconv.AppendBool(buf, f.num == 1)
+	default:
+		return appendJSONValue(buf, f.value)
+	}
+}
+
+// appendJSONValue appends the JSON encoding of a field value.
+// Errors are encoded as their message, and values that can't be encoded as their text representation.
+func appendJSONValue(buf []byte, value any) []byte {
+	// skip encoding/json for the most common types
+	switch v := value.(type) {
+	case error:
+		return appendJSONString(buf, nilSafeText(v, v.Error))
+	case string:
+		return appendJSONString(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case float64:
+		return appendJSONFloat(buf, v)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%+v", value))
+	}
+	return append(buf, data...)
+}
+
+// appendJSONFloat appends a float64 the way encoding/json encodes it. Infinities and NaN, which JSON
+// can't represent, are appended as strings.
+func appendJSONFloat(buf []byte, f float64) []byte {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		buf = append(buf, '"')
+		buf = strconv.AppendFloat(buf, f, 'g', -1, 64)
+		return append(buf, '"')
+	}
+
+	// use exponents for very small and very large numbers only, as encoding/json does
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	buf = strconv.AppendFloat(buf, f, format, -1, 64)
+	if format == 'e' {
+		// turn e-07 into e-7
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// hexDigits are used to escape control characters in JSON strings.
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string, escaped as with encoding/json: quotes, backslashes,
+// control characters, <, > and &, and the U+2028 and U+2029 separators are escaped,
+// and invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendCaller appends the directory, file name and line of the program counter, as in dir/file.go:12.
+func appendCaller(buf []byte, pc uintptr) []byte {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return append(buf, "???"...)
+	}
+
+	dir, file := filepath.Split(frame.File)
+	buf = append(buf, filepath.Base(dir)...)
+	buf = append(buf, '/')
+	buf = append(buf, file...)
+	buf = append(buf, ':')
+	return strconv.AppendInt(buf, int64(frame.Line), 10)
+}
@@ -0,0 +1,71 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	tt := map[string]string{
+		"plain":        "hello, world",
+		"quotes":       `say "hi" \ bye`,
+		"controls":     "a\tb\nc\rd\be\ff\x00g\x1f",
+		"html":         "<a href='x'>&</a>",
+		"unicode":      "héllo, 世界 🌍",
+		"separators":   "a b c",
+		"invalid utf8": "a\xffb\xc3",
+		"empty":        "",
+	}
+
+	for name, s := range tt {
+		t.Run(name, func(t *testing.T) {
+			expected, _ := json.Marshal(s)
+			if got := appendJSONString(nil, s); string(got) != string(expected) {
+				t.Errorf("expected %s, got %s", expected, got)
+			}
+		})
+	}
+}
+
+func FuzzAppendJSONString(f *testing.F) {
+	f.Add("hello")
+	f.Add("<\"\\\n \xff>")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		expected, _ := json.Marshal(s)
+		if got := appendJSONString(nil, s); string(got) != string(expected) {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	})
+}
+
+func TestAppendJSONFloat(t *testing.T) {
+	tt := map[string]float64{
+		"zero":         0,
+		"integer":      42,
+		"negative":     -1.5,
+		"small":        1e-7,
+		"tiny":         5e-324,
+		"large":        1e21,
+		"just below":   999999999999999999999,
+		"max":          math.MaxFloat64,
+		"fraction":     0.1,
+		"big fraction": 123456.789,
+	}
+
+	for name, f := range tt {
+		t.Run(name, func(t *testing.T) {
+			expected, _ := json.Marshal(f)
+			if got := appendJSONFloat(nil, f); string(got) != string(expected) {
+				t.Errorf("expected %s, got %s", expected, got)
+			}
+		})
+	}
+
+	for _, f := range []float64{math.Inf(1), math.Inf(-1), math.NaN()} {
+		if got := appendJSONFloat(nil, f); !json.Valid(got) {
+			t.Errorf("expected a valid JSON value for %v, got %s", f, got)
+		}
+	}
+}
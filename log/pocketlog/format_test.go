@@ -0,0 +1,156 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/pocketlog"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_FormatJSON(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatJSON))
+
+	before := time.Now()
+	testedLogger.With("request_id", "abc-123").Errorw(errorMessage, "user_id", 42, "err", errors.New("boom"))
+
+	if strings.Count(tw.contents, "\n") != 1 || !strings.HasSuffix(tw.contents, "\n") {
+		t.Fatalf("expected a single line, got %q", tw.contents)
+	}
+
+	// keys must keep the order in which fields were added
+	const expectedFields = `"fields":{"request_id":"abc-123","user_id":42,"err":"boom"}}`
+	if !strings.HasSuffix(tw.contents, expectedFields+"\n") {
+		t.Errorf("expected line to end with %s, got %q", expectedFields, tw.contents)
+	}
+
+	var got struct {
+		Time    time.Time      `json:"time"`
+		Level   string         `json:"level"`
+		Message string         `json:"message"`
+		Fields  map[string]any `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(tw.contents), &got); err != nil {
+		t.Fatalf("unable to decode %q: %s", tw.contents, err)
+	}
+
+	if got.Level != "error" {
+		t.Errorf("expected level %q, got %q", "error", got.Level)
+	}
+	if got.Message != errorMessage {
+		t.Errorf("expected message %q, got %q", errorMessage, got.Message)
+	}
+	if got.Time.Before(before.Truncate(time.Second)) {
+		t.Errorf("expected a timestamp after %s, got %s", before, got.Time)
+	}
+}
+
+func TestLogger_FormatJSON_NoFields(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatJSON))
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Infof("%s %q", "say", "hi")
+
+	if strings.Contains(tw.contents, "fields") {
+		t.Errorf("expected no fields object, got %q", tw.contents)
+	}
+	if !strings.HasSuffix(tw.contents, `"level":"info","message":"say \"hi\""}`+"\n") {
+		t.Errorf("unexpected contents %q", tw.contents)
+	}
+}
+
+func TestLogger_Decorations(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2022, 11, 7, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	}
+
+	tt := map[string]struct {
+		opts     []pocketlog.Option
+		expected string
+	}{
+		"none": {
+			opts:     nil,
+			expected: "INFO:\t" + infoMessage + "\n",
+		},
+		"prefix": {
+			opts:     []pocketlog.Option{pocketlog.WithPrefix("app ")},
+			expected: "app INFO:\t" + infoMessage + "\n",
+		},
+		"local timestamp": {
+			opts:     []pocketlog.Option{pocketlog.WithTimestamp("2006/01/02 15:04:05")},
+			expected: "2022/11/07 10:30:00 INFO:\t" + infoMessage + "\n",
+		},
+		"utc timestamp with default layout": {
+			opts:     []pocketlog.Option{pocketlog.WithTimestamp(""), pocketlog.WithUTC()},
+			expected: "2022-11-07T09:30:00Z INFO:\t" + infoMessage + "\n",
+		},
+		"all": {
+			opts:     []pocketlog.Option{pocketlog.WithPrefix("[app] "), pocketlog.WithTimestamp(time.Kitchen), pocketlog.WithUTC()},
+			expected: "[app] 9:30AM INFO:\t" + infoMessage + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			opts := append([]pocketlog.Option{pocketlog.WithOutput(tw), pocketlog.WithClock(clock)}, tc.opts...)
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, opts...)
+
+			testedLogger.Infof(infoMessage)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_WithCaller(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithCaller(),
+		pocketlog.WithExitFunc(func(int) {}))
+
+	// each logging method must report this file, at the line of the call
+	_, _, line, _ := runtime.Caller(0)
+	testedLogger.Debugf(debugMessage)
+	testedLogger.With("k", "v").Infow(infoMessage)
+	testedLogger.Named("db").Fatalf(errorMessage)
+	slog.New(pocketlog.NewSlogHandler(testedLogger)).Warn(debugMessage)
+
+	expected := fmt.Sprintf("pocketlog/format_test.go:%d: DEBUG:\t%s\n", line+1, debugMessage) +
+		fmt.Sprintf("pocketlog/format_test.go:%d: INFO:\t%s k=v\n", line+2, infoMessage) +
+		fmt.Sprintf("pocketlog/format_test.go:%d: FATAL:\tdb: %s\n", line+3, errorMessage) +
+		fmt.Sprintf("pocketlog/format_test.go:%d: WARN:\t%s\n", line+4, debugMessage)
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_FormatJSON_Decorations(t *testing.T) {
+	tw := &testWriter{}
+	clock := func() time.Time {
+		return time.Date(2022, 11, 7, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatJSON),
+		pocketlog.WithClock(clock), pocketlog.WithUTC(), pocketlog.WithCaller(), pocketlog.WithPrefix("ignored"))
+
+	_, _, line, _ := runtime.Caller(0)
+	testedLogger.Named("db").Infof(infoMessage)
+
+	expected := fmt.Sprintf(`{"time":"2022-11-07T09:30:00Z","level":"info","logger":"db","caller":"pocketlog/format_test.go:%d","message":%q}`+"\n",
+		line+1, infoMessage)
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %s, got %s", expected, tw.contents)
+	}
+}
@@ -0,0 +1,115 @@
+package pocketlog
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook processes entries before sinks receive them.
+// Hooks must not keep entries, whose fields may be reused once the entries are written.
+type Hook interface {
+	// Process returns the entry, possibly changed, and whether it should still be written.
+	// The fields of the entry may be shared with the logger: they must be copied before being changed.
+	// Values are read with Field.Any.
+	Process(e Entry) (Entry, bool)
+}
+
+// HookFunc is an adapter to use an ordinary function as a Hook.
+type HookFunc func(e Entry) (Entry, bool)
+
+// Process implements the Hook interface.
+func (f HookFunc) Process(e Entry) (Entry, bool) {
+	return f(e)
+}
+
+// levelHook is a hook that only runs for entries at or above a level.
+type levelHook struct {
+	threshold Level
+	hook      Hook
+}
+
+// CounterHook is a Hook that counts entries by logger name and level, and never changes them.
+// A CounterHook is safe for concurrent use by multiple goroutines.
+type CounterHook struct {
+	// counters holds an *atomic.Uint64 per counterKey.
+	counters sync.Map
+}
+
+// counterKey identifies the counter of an entry.
+type counterKey struct {
+	name  string
+	level Level
+}
+
+// NewCounterHook returns a hook that counts entries.
+func NewCounterHook() *CounterHook {
+	return &CounterHook{}
+}
+
+// Process implements the Hook interface.
+func (h *CounterHook) Process(e Entry) (Entry, bool) {
+	key := counterKey{name: e.Name, level: e.Level}
+
+	counter, ok := h.counters.Load(key)
+	if !ok {
+		counter, _ = h.counters.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+
+	return e, true
+}
+
+// Count returns the number of entries of the level logged by loggers with the name.
+func (h *CounterHook) Count(name string, lvl Level) uint64 {
+	counter, ok := h.counters.Load(counterKey{name: name, level: lvl})
+	if !ok {
+		return 0
+	}
+	return counter.(*atomic.Uint64).Load()
+}
+
+// Handler returns an HTTP handler that serves the counters in the Prometheus text format,
+// as the pocketlog_entries_total counter with logger and level labels.
+// The handler doesn't authenticate requests: only expose it on a trusted address.
+func (h *CounterHook) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(h.appendMetrics(nil))
+	})
+}
+
+// appendMetrics appends the counters in the Prometheus text format, sorted by name and level.
+func (h *CounterHook) appendMetrics(buf []byte) []byte {
+	var keys []counterKey
+	h.counters.Range(func(key, _ any) bool {
+		keys = append(keys, key.(counterKey))
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].level < keys[j].level
+	})
+
+	buf = append(buf, "# HELP pocketlog_entries_total Number of entries logged, by logger and level.\n"...)
+	buf = append(buf, "# TYPE pocketlog_entries_total counter\n"...)
+	for _, key := range keys {
+		buf = fmt.Appendf(buf, "pocketlog_entries_total{logger=\"%s\",level=\"%s\"} %d\n",
+			prometheusLabelReplacer.Replace(key.name), key.level, h.Count(key.name, key.level))
+	}
+	return buf
+}
+
+// prometheusLabelReplacer escapes the values of Prometheus labels.
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
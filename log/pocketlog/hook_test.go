@@ -0,0 +1,75 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithHook(t *testing.T) {
+	tw := &testWriter{}
+	var seen []string
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw),
+		pocketlog.WithHook(pocketlog.LevelInfo, pocketlog.HookFunc(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+			seen = append(seen, e.Message)
+			if strings.Contains(e.Message, "noise") {
+				return e, false
+			}
+			e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], pocketlog.String("hooked", "yes"))
+			return e, true
+		})))
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Infof("some noise")
+	testedLogger.Errorw(errorMessage, "code", 7)
+
+	expected := "DEBUG:\t" + debugMessage + "\n" + "ERROR:\t" + errorMessage + " code=7 hooked=yes\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected the hook to only see entries at or above info, got %q", seen)
+	}
+}
+
+func TestCounterHook(t *testing.T) {
+	counter := pocketlog.NewCounterHook()
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(failingSink{}), pocketlog.WithHook(pocketlog.LevelDebug, counter))
+
+	lgr.Named("db").Errorf(errorMessage)
+	lgr.Named("db").Errorf(errorMessage)
+	lgr.Named(`we"ird`).Warnf("careful")
+	lgr.Infof(infoMessage)
+
+	if got := counter.Count("db", pocketlog.LevelError); got != 2 {
+		t.Errorf("expected 2 errors for db, got %d", got)
+	}
+	if got := counter.Count("db", pocketlog.LevelInfo); got != 0 {
+		t.Errorf("expected no info entries for db, got %d", got)
+	}
+
+	resp := httptest.NewRecorder()
+	counter.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	expected := `# HELP pocketlog_entries_total Number of entries logged, by logger and level.
+# TYPE pocketlog_entries_total counter
+pocketlog_entries_total{logger="",level="info"} 1
+pocketlog_entries_total{logger="db",level="error"} 2
+pocketlog_entries_total{logger="we\"ird",level="warn"} 1
+`
+	if resp.Body.String() != expected {
+		t.Errorf("invalid metrics, expected %q, got %q", expected, resp.Body.String())
+	}
+	if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("invalid content type %q", ct)
+	}
+
+	resp = httptest.NewRecorder()
+	counter.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if resp.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package pocketlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JournaldSocket is the path of the socket that journald reads native protocol messages from.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldSink is a Sink that sends entries to journald, with its native protocol.
+// Fields are sent as journal fields, with their keys in upper case and characters other than
+// letters, digits and underscores replaced with underscores.
+// Entries larger than the maximum datagram size of the socket fail to be sent.
+// A JournaldSink is safe for concurrent use by multiple goroutines.
+type JournaldSink struct {
+	threshold Level
+	cfg       syslogConfig
+
+	addr *net.UnixAddr
+
+	// mu protects conn, and serializes messages.
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournaldSink returns a sink that sends entries at or above threshold to the journald socket
+// at socketPath, usually JournaldSocket. It accepts the WithFacility and WithAppName options.
+func NewJournaldSink(socketPath string, threshold Level, opts ...SyslogOption) (*JournaldSink, error) {
+	s := &JournaldSink{
+		threshold: threshold,
+		cfg:       newSyslogConfig(opts),
+		addr:      &net.UnixAddr{Name: socketPath, Net: "unixgram"},
+	}
+
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Enabled implements the Sink interface.
+func (s *JournaldSink) Enabled(lvl Level) bool {
+	return lvl >= s.threshold
+}
+
+// Log implements the Sink interface. If sending the message fails, it reconnects and tries once more,
+// in case journald restarted.
+func (s *JournaldSink) Log(e Entry) error {
+	msg := s.format(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if _, err := s.conn.Write(msg); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.dial(); err != nil {
+		return err
+	}
+
+	if _, err := s.conn.Write(msg); err != nil {
+		return fmt.Errorf("unable to send entry to journald: %w", err)
+	}
+	return nil
+}
+
+// Close closes the socket of the sink.
+func (s *JournaldSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// dial connects to the journald socket. It must be called with mu held, or before the sink is shared.
+func (s *JournaldSink) dial() error {
+	conn, err := net.DialUnix("unixgram", nil, s.addr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to journald at %s: %w", s.addr.Name, err)
+	}
+
+	s.conn = conn
+	return nil
+}
+
+// format returns the native protocol message of the entry.
+func (s *JournaldSink) format(e Entry) []byte {
+	buf := make([]byte, 0, 128+len(e.Message))
+
+	buf = appendJournalField(buf, "MESSAGE", e.Message)
+	buf = appendJournalField(buf, "PRIORITY", strconv.Itoa(e.Level.Severity()))
+	buf = appendJournalField(buf, "SYSLOG_FACILITY", strconv.Itoa(int(s.cfg.facility)))
+	buf = appendJournalField(buf, "SYSLOG_IDENTIFIER", s.cfg.appName)
+	if e.Name != "" {
+		buf = appendJournalField(buf, "LOGGER", e.Name)
+	}
+
+	if e.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{e.PC}).Next()
+		buf = appendJournalField(buf, "CODE_FILE", frame.File)
+		buf = appendJournalField(buf, "CODE_LINE", strconv.Itoa(frame.Line))
+		buf = appendJournalField(buf, "CODE_FUNC", frame.Function)
+	}
+
+	for _, f := range e.Fields {
+		buf = appendJournalField(buf, journalFieldName(f.Key), f.text())
+	}
+
+	return buf
+}
+
+// appendJournalField appends a field as NAME=value, or in the binary form for values
+// with new lines: the name, a new line, the length of the value as a little-endian uint64, and the value.
+func appendJournalField(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+
+	if !strings.Contains(value, "\n") {
+		buf = append(buf, '=')
+		buf = append(buf, value...)
+		return append(buf, '\n')
+	}
+
+	buf = append(buf, '\n')
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
+
+// journalFieldName returns a valid journal field name for the key: at most 64 upper-case letters,
+// digits and underscores, not starting with an underscore or a digit, which journald reserves or rejects.
+func journalFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key) && len(name) < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		name = append(name, c)
+	}
+
+	if len(name) == 0 || name[0] == '_' || (name[0] >= '0' && name[0] <= '9') {
+		name = append([]byte("F"), name...)
+		if len(name) > 64 {
+			name = name[:64]
+		}
+	}
+	return string(name)
+}
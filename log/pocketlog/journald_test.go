@@ -0,0 +1,53 @@
+package pocketlog_test
+
+import (
+	"encoding/binary"
+	"log/pocketlog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournaldSink(t *testing.T) {
+	// socket paths are limited to about 100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "journald")
+	if err != nil {
+		t.Fatalf("unable to create directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer conn.Close()
+
+	sink, err := pocketlog.NewJournaldSink(socketPath, pocketlog.LevelInfo,
+		pocketlog.WithAppName("app"), pocketlog.WithFacility(pocketlog.FacilityDaemon))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink)).Named("db")
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Errorw("line 1\nline 2", "user.id", 42, "_private", true)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("unable to read message: %s", err)
+	}
+
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len("line 1\nline 2")))
+	expected := "MESSAGE\n" + string(length) + "line 1\nline 2\n" +
+		"PRIORITY=3\nSYSLOG_FACILITY=3\nSYSLOG_IDENTIFIER=app\nLOGGER=db\nUSER_ID=42\nF_PRIVATE=true\n"
+	if got := string(buf[:n]); got != expected {
+		t.Errorf("invalid message, expected %q, got %q", expected, got)
+	}
+}
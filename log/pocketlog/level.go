@@ -1,5 +1,10 @@
 package pocketlog
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Level represents an available logging level
 type Level byte
 
@@ -8,6 +13,89 @@ const (
 	LevelDebug Level = iota
 	// LevelInfo represents a logging level that contains information deemed valuable.
 	LevelInfo
-	// LevelError represents the highest logging level, only to be used to trace errors.
+	// LevelWarn represents a logging level for unexpected events that don't prevent the program from working.
+	LevelWarn
+	// LevelError represents a logging level only to be used to trace errors.
 	LevelError
+	// LevelFatal represents the highest logging level, used right before the program exits.
+	LevelFatal
 )
+
+// ErrUnknownLevel is returned when a text doesn't hold the name of a level
+const ErrUnknownLevel = pocketlogError("unknown level")
+
+// String implements the fmt.Stringer interface. It returns the lowercase name of the level.
+func (lvl Level) String() string {
+	switch lvl {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	default:
+		return fmt.Sprintf("level(%d)", lvl)
+	}
+}
+
+// ParseLevel returns the level named by s, ignoring case.
+// It also accepts "warning" for LevelWarn.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, s)
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (lvl Level) MarshalText() ([]byte, error) {
+	if lvl > LevelFatal {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownLevel, lvl)
+	}
+	return []byte(lvl.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface,
+// so that levels can be read from flags with flag.TextVar, or from JSON.
+func (lvl *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*lvl = parsed
+	return nil
+}
+
+// prefix returns the text that precedes messages of this level. It is never empty,
+// as unknown levels fall back to their String representation, such as "LEVEL(9):\t".
+func (lvl Level) prefix() string {
+	switch lvl {
+	case LevelDebug:
+		return "DEBUG:\t"
+	case LevelInfo:
+		return "INFO:\t"
+	case LevelWarn:
+		return "WARN:\t"
+	case LevelError:
+		return "ERROR:\t"
+	case LevelFatal:
+		return "FATAL:\t"
+	default:
+		return strings.ToUpper(lvl.String()) + ":\t"
+	}
+}
@@ -0,0 +1,101 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/pocketlog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tt := map[string]struct {
+		input    string
+		expected pocketlog.Level
+		err      error
+	}{
+		"debug":           {input: "debug", expected: pocketlog.LevelDebug},
+		"upper case info": {input: "INFO", expected: pocketlog.LevelInfo},
+		"warn":            {input: "warn", expected: pocketlog.LevelWarn},
+		"warning":         {input: "Warning", expected: pocketlog.LevelWarn},
+		"padded error":    {input: " error ", expected: pocketlog.LevelError},
+		"fatal":           {input: "fatal", expected: pocketlog.LevelFatal},
+		"unknown":         {input: "verbose", err: pocketlog.ErrUnknownLevel},
+		"empty":           {input: "", err: pocketlog.ErrUnknownLevel},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			lvl, err := pocketlog.ParseLevel(tc.input)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected err %v, got %v", tc.err, err)
+			}
+
+			if lvl != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, lvl)
+			}
+		})
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	for lvl := pocketlog.LevelDebug; lvl <= pocketlog.LevelFatal; lvl++ {
+		parsed, err := pocketlog.ParseLevel(lvl.String())
+		if err != nil {
+			t.Fatalf("unable to parse %q: %s", lvl, err)
+		}
+
+		if parsed != lvl {
+			t.Errorf("expected %v, got %v", lvl, parsed)
+		}
+	}
+
+	if got := pocketlog.Level(42).String(); got != "level(42)" {
+		t.Errorf("expected %q, got %q", "level(42)", got)
+	}
+}
+
+func TestLevel_MarshalText(t *testing.T) {
+	type config struct {
+		Level pocketlog.Level `json:"level"`
+	}
+
+	data, err := json.Marshal(config{Level: pocketlog.LevelWarn})
+	if err != nil {
+		t.Fatalf("unable to marshal level: %s", err)
+	}
+	if string(data) != `{"level":"warn"}` {
+		t.Errorf("expected %s, got %s", `{"level":"warn"}`, data)
+	}
+
+	var cfg config
+	if err = json.Unmarshal([]byte(`{"level":"ERROR"}`), &cfg); err != nil {
+		t.Fatalf("unable to unmarshal level: %s", err)
+	}
+	if cfg.Level != pocketlog.LevelError {
+		t.Errorf("expected %v, got %v", pocketlog.LevelError, cfg.Level)
+	}
+
+	if err = json.Unmarshal([]byte(`{"level":"loud"}`), &cfg); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected err %v, got %v", pocketlog.ErrUnknownLevel, err)
+	}
+
+	if _, err = pocketlog.Level(42).MarshalText(); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected err %v, got %v", pocketlog.ErrUnknownLevel, err)
+	}
+}
+
+func TestLevel_Flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	var lvl pocketlog.Level
+	fs.TextVar(&lvl, "level", pocketlog.LevelInfo, "the logging threshold")
+
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil {
+		t.Fatalf("unable to parse flags: %s", err)
+	}
+
+	if lvl != pocketlog.LevelDebug {
+		t.Errorf("expected %v, got %v", pocketlog.LevelDebug, lvl)
+	}
+}
@@ -0,0 +1,68 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// levelsResponse is the JSON representation of the thresholds of a logger.
+type levelsResponse struct {
+	Level Level            `json:"level"`
+	Named map[string]Level `json:"named"`
+}
+
+// levelRequest is the JSON body of a request that changes a threshold.
+type levelRequest struct {
+	Level *Level `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler that reads and changes the thresholds of the logger,
+// of its parents and of their children:
+//   - GET returns the shared threshold and the overrides, as {"level":"info","named":{"db":"debug"}}.
+//   - PUT with a {"level":"debug"} body changes the shared threshold, or the override
+//     of a name when the request has a name query parameter.
+//   - DELETE with a name query parameter removes the override of the name.
+//
+// The handler doesn't authenticate requests: only expose it on a trusted address.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid body: %s", err), http.StatusBadRequest)
+				return
+			}
+			if req.Level == nil {
+				http.Error(w, "invalid body: missing level", http.StatusBadRequest)
+				return
+			}
+
+			if name == "" {
+				l.thresholds.set(*req.Level)
+			} else {
+				l.SetNamedLevel(name, *req.Level)
+			}
+		case http.MethodDelete:
+			if name == "" {
+				http.Error(w, "missing name query parameter", http.StatusBadRequest)
+				return
+			}
+			l.UnsetNamedLevel(name)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelsResponse{
+			Level: l.thresholds.level(""),
+			Named: l.NamedLevels(),
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_LevelHandler(t *testing.T) {
+	type testCase struct {
+		method     string
+		target     string
+		body       string
+		statusCode int
+		response   string
+	}
+
+	// the cases run in order, each one on the thresholds left by the previous one
+	steps := []struct {
+		name string
+		testCase
+	}{
+		{"initial levels", testCase{http.MethodGet, "/", "", http.StatusOK, `{"level":"info","named":{}}`}},
+		{"override db", testCase{http.MethodPut, "/?name=db", `{"level":"debug"}`, http.StatusOK, `{"level":"info","named":{"db":"debug"}}`}},
+		{"change shared level", testCase{http.MethodPut, "/", `{"level":"error"}`, http.StatusOK, `{"level":"error","named":{"db":"debug"}}`}},
+		{"unknown level", testCase{http.MethodPut, "/", `{"level":"loud"}`, http.StatusBadRequest, ""}},
+		{"missing level", testCase{http.MethodPut, "/", `{}`, http.StatusBadRequest, ""}},
+		{"delete without name", testCase{http.MethodDelete, "/", "", http.StatusBadRequest, ""}},
+		{"unsupported method", testCase{http.MethodPost, "/", "", http.StatusMethodNotAllowed, ""}},
+		{"remove db override", testCase{http.MethodDelete, "/?name=db", "", http.StatusOK, `{"level":"error","named":{}}`}},
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo)
+	handler := lgr.Named("http").LevelHandler()
+
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(step.method, step.target, strings.NewReader(step.body)))
+
+		if rec.Code != step.statusCode {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.statusCode, rec.Code)
+		}
+		if step.response != "" && strings.TrimSpace(rec.Body.String()) != step.response {
+			t.Errorf("%s: expected response %s, got %s", step.name, step.response, rec.Body.String())
+		}
+	}
+}
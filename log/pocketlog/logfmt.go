@@ -0,0 +1,239 @@
+package pocketlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidLogfmt is returned by ParseLogfmt for lines that aren't valid logfmt.
+const ErrInvalidLogfmt = pocketlogError("invalid logfmt")
+
+// appendLogfmt appends the logfmt representation of the entry to buf: the time, level, logger,
+// caller and msg keys, followed by the fields. The time, logger and caller are omitted when the entry
+// has none. Keys are sanitized and values are quoted when needed, so that logfmt parsers such as
+// those of go-logfmt and Loki read them back.
+func appendLogfmt(buf []byte, e Entry) []byte {
+	if !e.Time.IsZero() {
+		buf = append(buf, "time="...)
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, ' ')
+	}
+
+	buf = append(buf, "level="...)
+	buf = append(buf, e.Level.String()...)
+	if e.Name != "" {
+		buf = append(buf, " logger="...)
+		buf = appendLogfmtValue(buf, e.Name)
+	}
+	if e.PC != 0 {
+		buf = append(buf, " caller="...)
+		buf = appendLogfmtValue(buf, string(appendCaller(nil, e.PC)))
+	}
+	buf = append(buf, " msg="...)
+	buf = appendLogfmtValue(buf, e.Message)
+
+	for _, f := range e.Fields {
+		buf = append(buf, ' ')
+		buf = appendLogfmtKey(buf, f.Key)
+		buf = append(buf, '=')
+
+		switch f.kind {
+		case kindString:
+			buf = appendLogfmtValue(buf, f.str)
+		case kindAny:
+			buf = appendLogfmtValue(buf, formatValue(f.value))
+		default:
+			// numbers, booleans and durations never need quoting
+			buf = appendTextFieldValue(buf, f)
+		}
+	}
+
+	return append(buf, '\n')
+}
+
+// appendLogfmtKey appends the key to buf, with an underscore in place of each character that can't appear
+// in a logfmt key: spaces, equal signs, quotes, and characters that aren't printable. An empty key becomes "_".
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+
+	for _, r := range key {
+		if r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf
+}
+
+// appendLogfmtValue appends the value to buf, quoted if it is empty or contains spaces, equal signs, quotes,
+// or characters that aren't printable. Quoted values only use the \", \\, \n, \r, \t and \u escape sequences,
+// and invalid UTF-8 bytes are replaced with U+FFFD.
+func appendLogfmtValue(buf []byte, s string) []byte {
+	if !needsQuoting(s) {
+		return append(buf, s...)
+	}
+
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, '\\', 'n')
+		case r == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == '\t':
+			buf = append(buf, '\\', 't')
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) || r == '\u2028' || r == '\u2029':
+			buf = append(buf, '\\', 'u', hex[r>>12], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+		default:
+			// invalid bytes come as utf8.RuneError, which is appended as U+FFFD
+			buf = utf8.AppendRune(buf, r)
+		}
+	}
+	return append(buf, '"')
+}
+
+// ParseLogfmt returns the key=value pairs of a logfmt line, in order, as fields with string values.
+// Keys and values may be quoted, with the \", \\, \n, \r, \t and \uXXXX escape sequences. A key without a value,
+// as in "key" instead of "key=value", has an empty value. A trailing newline is ignored.
+func ParseLogfmt(line []byte) ([]Field, error) {
+	var fields []Field
+
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+			continue
+		}
+
+		key, next, err := parseLogfmtToken(line, i, true)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" && line[i] != '"' {
+			return nil, fmt.Errorf("%w: missing key at offset %d", ErrInvalidLogfmt, i)
+		}
+
+		value := ""
+		if next < len(line) && line[next] == '=' {
+			if value, next, err = parseLogfmtToken(line, next+1, false); err != nil {
+				return nil, err
+			}
+		}
+
+		if next < len(line) && !isLogfmtSpace(line[next]) {
+			return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidLogfmt, line[next], next)
+		}
+
+		fields = append(fields, String(key, value))
+		i = next
+	}
+
+	return fields, nil
+}
+
+// parseLogfmtToken returns the key or value that starts at the offset, and the offset that follows it.
+// Unquoted keys end at an equal sign or a space, and unquoted values at a space.
+func parseLogfmtToken(line []byte, start int, isKey bool) (string, int, error) {
+	if start < len(line) && line[start] == '"' {
+		end := start + 1
+		for ; end < len(line) && line[end] != '"'; end++ {
+			if line[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(line) {
+			return "", 0, fmt.Errorf("%w: unterminated quoted string at offset %d", ErrInvalidLogfmt, start)
+		}
+
+		s, err := unquoteLogfmt(line[start+1 : end])
+		if err != nil {
+			return "", 0, fmt.Errorf("%w: invalid quoted string at offset %d: %w", ErrInvalidLogfmt, start, err)
+		}
+		return s, end + 1, nil
+	}
+
+	end := start
+	for ; end < len(line) && !isLogfmtSpace(line[end]) && !(isKey && line[end] == '='); end++ {
+		if line[end] == '"' {
+			return "", 0, fmt.Errorf("%w: unexpected quote at offset %d", ErrInvalidLogfmt, end)
+		}
+	}
+	return string(line[start:end]), end, nil
+}
+
+// isLogfmtSpace reports whether c separates pairs.
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// unquoteLogfmt returns the text between the quotes of a quoted key or value, with its escape sequences replaced.
+func unquoteLogfmt(quoted []byte) (string, error) {
+	if bytes.IndexByte(quoted, '\\') < 0 {
+		return string(quoted), nil
+	}
+
+	buf := make([]byte, 0, len(quoted))
+	for i := 0; i < len(quoted); i++ {
+		if quoted[i] != '\\' {
+			buf = append(buf, quoted[i])
+			continue
+		}
+
+		// the quoted text never ends with a backslash, which would escape the closing quote
+		i++
+		switch c := quoted[i]; c {
+		case '"', '\\':
+			buf = append(buf, c)
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, n, err := parseLogfmtRune(quoted[i+1:])
+			if err != nil {
+				return "", err
+			}
+			buf = utf8.AppendRune(buf, r)
+			i += n
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c", c)
+		}
+	}
+	return string(buf), nil
+}
+
+// parseLogfmtRune parses the hexadecimal digits that follow \u, and the low surrogate that follows
+// a high surrogate. It returns the rune and the number of bytes it read.
+func parseLogfmtRune(b []byte) (rune, int, error) {
+	if len(b) < 4 {
+		return 0, 0, errors.New("invalid escape sequence \\u")
+	}
+	v, err := strconv.ParseUint(string(b[:4]), 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid escape sequence \\u%s", b[:4])
+	}
+
+	r := rune(v)
+	if utf16.IsSurrogate(r) && len(b) >= 10 && b[4] == '\\' && b[5] == 'u' {
+		if low, err := strconv.ParseUint(string(b[6:10]), 16, 16); err == nil {
+			if pair := utf16.DecodeRune(r, rune(low)); pair != utf8.RuneError {
+				return pair, 10, nil
+			}
+		}
+	}
+	return r, 4, nil
+}
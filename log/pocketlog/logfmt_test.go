@@ -0,0 +1,125 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"log/pocketlog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestLogger_FormatLogfmt(t *testing.T) {
+	tw := &testWriter{}
+	now := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatLogfmt),
+		pocketlog.WithClock(func() time.Time { return now }), pocketlog.WithPrefix("ignored"))
+
+	testedLogger.Named("http").Infow(`say "hi"`, "path", "/a b", "status", 200, "empty", "", "key=x", "a=b", `my "key"`, "a\\b\n\x01\xff")
+
+	expected := `time=2024-03-01T12:30:00.0000005Z level=info logger=http msg="say \"hi\"" path="/a b" status=200 empty="" key_x="a=b"` +
+		` my__key_="a\\b\n\u0001` + "\uFFFD\"\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	tt := map[string]struct {
+		line     string
+		expected []pocketlog.Field
+	}{
+		"pairs": {
+			line:     "level=info msg=hello status=200\n",
+			expected: []pocketlog.Field{pocketlog.String("level", "info"), pocketlog.String("msg", "hello"), pocketlog.String("status", "200")},
+		},
+		"quoted": {
+			line:     `msg="a \"b\"\n\tc" "my key"="" path=/x`,
+			expected: []pocketlog.Field{pocketlog.String("msg", "a \"b\"\n\tc"), pocketlog.String("my key", ""), pocketlog.String("path", "/x")},
+		},
+		"unicode escapes": {
+			line:     `msg="\u00e9\u0001 \ud83d\ude00"`,
+			expected: []pocketlog.Field{pocketlog.String("msg", "\u00e9\x01 \U0001F600")},
+		},
+		"bare key and spaces": {
+			line:     "  debug   a=  b=1\t",
+			expected: []pocketlog.Field{pocketlog.String("debug", ""), pocketlog.String("a", ""), pocketlog.String("b", "1")},
+		},
+		"empty": {
+			line:     "",
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := pocketlog.ParseLogfmt([]byte(tc.line))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseLogfmt_Errors(t *testing.T) {
+	tt := map[string]string{
+		"missing key":        "=value",
+		"unterminated quote": `msg="hello`,
+		"invalid escape":     `msg="\q"`,
+		"go escape":          `msg="\x41"`,
+		"short unicode":      `msg="\u12"`,
+		"stray quote":        `msg=a"b`,
+		"garbage after":      `msg="a"b`,
+	}
+
+	for name, line := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := pocketlog.ParseLogfmt([]byte(line)); !errors.Is(err, pocketlog.ErrInvalidLogfmt) {
+				t.Errorf("expected ErrInvalidLogfmt, got %v", err)
+			}
+		})
+	}
+}
+
+func FuzzLogfmt(f *testing.F) {
+	f.Add("hello", "key", "value")
+	f.Add(`say "hi"`, "a=b", "")
+	f.Add("\x00\xff", " ", "\\\"\n")
+
+	f.Fuzz(func(t *testing.T, msg, key, value string) {
+		tw := &testWriter{}
+		// entries without a time have no time key
+		lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(pocketlog.FormatLogfmt),
+			pocketlog.WithClock(func() time.Time { return time.Time{} }))
+		lgr.Info(msg, pocketlog.String(key, value))
+
+		got, err := pocketlog.ParseLogfmt([]byte(tw.contents))
+		if err != nil {
+			t.Fatalf("unable to parse %q: %s", tw.contents, err)
+		}
+
+		// keys are sanitized, and invalid UTF-8 bytes are replaced with U+FFFD
+		sanitized := strings.Map(func(r rune) rune {
+			if r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+				return '_'
+			}
+			return r
+		}, key)
+		if sanitized == "" {
+			sanitized = "_"
+		}
+
+		expected := []pocketlog.Field{
+			pocketlog.String("level", "info"), pocketlog.String("msg", string([]rune(msg))), pocketlog.String(sanitized, string([]rune(value))),
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v from %q", expected, got, tw.contents)
+		}
+	})
+}
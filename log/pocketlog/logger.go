@@ -1,67 +1,499 @@
 package pocketlog
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"sync"
+	"time"
 )
 
 // Logger is used to log information.
+// A Logger is safe for concurrent use by multiple goroutines.
 type Logger struct {
-	threshold Level
-	output    io.Writer
+	// thresholds is shared with the children of the logger.
+	thresholds *thresholds
+	name       string
+
+	output  io.Writer
+	encoder encoder
+	now     func() time.Time
+	utc     bool
+	caller  bool
+	// errorStack tells whether Err captures stack traces.
+	errorStack bool
+	// maxLength is the maximum length of messages and field values, in bytes. Zero means no limit.
+	maxLength int
+	fields    []Field
+	exit      func(code int)
+	onError   func(err error)
+	redactors []Redactor
+	hooks     []levelHook
+	// extractors return the fields that a context holds, in addition to those added with ContextWithFields.
+	extractors []func(ctx context.Context) []Field
+
+	// sinks receive the entries of the logger. They are shared with the children of the logger.
+	sinks []Sink
+
+	// sampler is only set for loggers that sample their entries. It is shared with the children of the logger.
+	sampler *sampler
+
+	// queue is only set for asynchronous loggers. It is shared with the children of the logger.
+	queue          *asyncQueue
+	queueSize      int
+	overflowPolicy OverflowPolicy
+
+	// reuseFields tells whether the fields of entries can be reused once they are written,
+	// which is the case when entries aren't queued, and only writer sinks receive them.
+	reuseFields bool
+
+	// closeOutputs closes the files that FromConfig opened, only once. It is shared with the children of the logger.
+	closeOutputs func()
+	// closeSinks closes the sinks that implement io.Closer, only once. It is shared with the children of the logger.
+	closeSinks func()
 }
 
 // New returns a logger, ready to log at the required threshold.
 func New(threshold Level, opts ...Option) *Logger {
 	// set defaults
-	lgr := &Logger{threshold: threshold, output: os.Stdout}
+	lgr := &Logger{thresholds: newThresholds(threshold), output: os.Stdout, now: time.Now, exit: os.Exit}
 
 	// add config options
-	for _, configFunc := range opts {
-		configFunc(lgr)
+	for _, opt := range opts {
+		opt.apply(lgr)
+	}
+
+	if len(lgr.sinks) == 0 {
+		lgr.sinks = []Sink{newWriterSink(lgr.output, LevelDebug, lgr.encoder)}
+	}
+
+	if lgr.queueSize > 0 {
+		lgr.queue = newAsyncQueue(lgr.queueSize, lgr.overflowPolicy, lgr.write)
+	}
+
+	if lgr.sampler != nil {
+		lgr.sampler.start(func() {
+			lgr.logSummaries(lgr.sampler.flush(lgr.now()))
+		})
+	}
+
+	lgr.closeSinks = sync.OnceFunc(lgr.closeClosers)
+
+	lgr.reuseFields = lgr.queue == nil
+	for _, s := range lgr.sinks {
+		// other sinks may keep entries
+		if _, ok := s.(*writerSink); !ok {
+			lgr.reuseFields = false
+		}
 	}
 
 	return lgr
 }
 
+// With returns a child logger that adds the given key/value pairs to every
+// entry it prints, after the fields of its parent.
+// Keys should be strings; see Debugw for how malformed pairs are handled.
+func (l *Logger) With(keysAndValues ...any) *Logger {
+	child := *l
+	// cap the parent's slice so that siblings never share an appended array
+	child.fields = append(l.fields[:len(l.fields):len(l.fields)], fieldsFromPairs(keysAndValues)...)
+	return &child
+}
+
+// WithFields returns a child logger that adds the given fields to every entry it prints,
+// after the fields of its parent.
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	child := *l
+	// cap the parent's slice so that siblings never share an appended array
+	child.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	return &child
+}
+
+// Named returns a child logger whose name is the name of its parent, followed by a dot and name.
+// Named loggers print their name before the message, and can be given their own threshold
+// with SetNamedLevel, or with their SetLevel method.
+func (l *Logger) Named(name string) *Logger {
+	child := *l
+	if l.name == "" {
+		child.name = name
+	} else {
+		child.name = l.name + nameSeparator + name
+	}
+	return &child
+}
+
+// Name returns the name of the logger, or an empty string for a logger that wasn't named.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Enabled reports whether the logger prints messages of the given level.
+func (l *Logger) Enabled(lvl Level) bool {
+	return lvl >= l.thresholds.level(l.name)
+}
+
+// Level returns the threshold of the logger, taking the overrides of its name into account.
+func (l *Logger) Level() Level {
+	return l.thresholds.level(l.name)
+}
+
+// SetLevel changes the threshold of the logger. For a logger returned by Named, it overrides the threshold
+// of its name, as SetNamedLevel does, which also applies to its children. Otherwise, it changes the shared
+// threshold of the logger, of its parents, and of all their children, except for the names that have an override.
+// It is safe to call while other goroutines log.
+func (l *Logger) SetLevel(lvl Level) {
+	if l.name != "" {
+		l.thresholds.setNamed(l.name, lvl)
+		return
+	}
+	l.thresholds.set(lvl)
+}
+
+// SetNamedLevel overrides the threshold of the loggers with the given name and of their children.
+// For instance, an override for "db" applies to loggers named "db" and "db.sql", but not "dbx".
+func (l *Logger) SetNamedLevel(name string, lvl Level) {
+	l.thresholds.setNamed(name, lvl)
+}
+
+// UnsetNamedLevel removes the override of the given name.
+func (l *Logger) UnsetNamedLevel(name string) {
+	l.thresholds.unsetNamed(name)
+}
+
+// NamedLevels returns a copy of the threshold overrides, by name.
+func (l *Logger) NamedLevels() map[string]Level {
+	return l.thresholds.named()
+}
+
 // Debugf formats and prints a message if the log level is debug or higher.
 // The default output is Stdout.
 func (l *Logger) Debugf(format string, args ...any) {
-	// validate it is the correct logger
-	if l.threshold > LevelDebug {
+	if !l.Enabled(LevelDebug) {
 		return
 	}
-	format = fmt.Sprintf("DEBUG:\t%s", format)
 
-	l.logf(l.output, format, args...)
+	l.logf(LevelDebug, format, fmt.Sprintf(format, args...), nil, nil)
 }
 
 // Infof formats and prints a message if the log level is info or higher.
 func (l *Logger) Infof(format string, args ...any) {
-	// validate it is the correct logger
-	if l.threshold > LevelInfo {
+	if !l.Enabled(LevelInfo) {
 		return
 	}
 
-	format = fmt.Sprintf("INFO:\t%s", format)
+	l.logf(LevelInfo, format, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Warnf formats and prints a message if the log level is warn or higher.
+func (l *Logger) Warnf(format string, args ...any) {
+	if !l.Enabled(LevelWarn) {
+		return
+	}
 
-	l.logf(l.output, format, args...)
+	l.logf(LevelWarn, format, fmt.Sprintf(format, args...), nil, nil)
 }
 
-// Errorf formats and prints a message if the log level is error.
+// Errorf formats and prints a message if the log level is error or higher.
 func (l *Logger) Errorf(format string, args ...any) {
-	format = fmt.Sprintf("ERROR:\t%s", format)
-	l.logf(l.output, format, args...)
+	if !l.Enabled(LevelError) {
+		return
+	}
+
+	l.logf(LevelError, format, fmt.Sprintf(format, args...), nil, nil)
 }
 
-// logf prints the message to the output.
-// Add decorations here, if any.
-func (l *Logger) logf(output io.Writer, format string, args ...any) {
-	_, _ = fmt.Fprintf(output, format+"\n", args...)
+// Fatalf formats and prints a message whatever the log level, waits for it to be written,
+// then calls the exit function with status 1. The default exit function is os.Exit.
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(LevelFatal, format, fmt.Sprintf(format, args...), nil, nil)
+	l.Flush()
+	l.exit(1)
 }
 
-type jsonLog struct {
-	Level   Level  `json:"level"`
-	Message string `json:"message"`
+// Debugw prints a message followed by key/value pairs if the log level is debug or higher.
+// A key that is not a string, or a trailing key without a value, is printed under the !BADKEY key.
+func (l *Logger) Debugw(msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+
+	l.logf(LevelDebug, msg, msg, nil, keysAndValues)
+}
+
+// Infow prints a message followed by key/value pairs if the log level is info or higher.
+func (l *Logger) Infow(msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+
+	l.logf(LevelInfo, msg, msg, nil, keysAndValues)
+}
+
+// Warnw prints a message followed by key/value pairs if the log level is warn or higher.
+func (l *Logger) Warnw(msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelWarn) {
+		return
+	}
+
+	l.logf(LevelWarn, msg, msg, nil, keysAndValues)
+}
+
+// Errorw prints a message followed by key/value pairs if the log level is error or higher.
+func (l *Logger) Errorw(msg string, keysAndValues ...any) {
+	if !l.Enabled(LevelError) {
+		return
+	}
+
+	l.logf(LevelError, msg, msg, nil, keysAndValues)
+}
+
+// Fatalw prints a message followed by key/value pairs whatever the log level,
+// waits for it to be written, then calls the exit function with status 1.
+func (l *Logger) Fatalw(msg string, keysAndValues ...any) {
+	l.logf(LevelFatal, msg, msg, nil, keysAndValues)
+	l.Flush()
+	l.exit(1)
+}
+
+// Debug prints a message followed by the fields if the log level is debug or higher.
+// Unlike Debugf and Debugw, it doesn't allocate when the level is disabled, and it avoids
+// allocations when the fields are built with the typed constructors, such as String and Int.
+func (l *Logger) Debug(msg string, fields ...Field) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+
+	l.logf(LevelDebug, msg, msg, fields, nil)
+}
+
+// Info prints a message followed by the fields if the log level is info or higher.
+func (l *Logger) Info(msg string, fields ...Field) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+
+	l.logf(LevelInfo, msg, msg, fields, nil)
+}
+
+// Warn prints a message followed by the fields if the log level is warn or higher.
+func (l *Logger) Warn(msg string, fields ...Field) {
+	if !l.Enabled(LevelWarn) {
+		return
+	}
+
+	l.logf(LevelWarn, msg, msg, fields, nil)
+}
+
+// Error prints a message followed by the fields if the log level is error or higher.
+func (l *Logger) Error(msg string, fields ...Field) {
+	if !l.Enabled(LevelError) {
+		return
+	}
+
+	l.logf(LevelError, msg, msg, fields, nil)
+}
+
+// Fatal prints a message followed by the fields whatever the log level,
+// waits for it to be written, then calls the exit function with status 1.
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.logf(LevelFatal, msg, msg, fields, nil)
+	l.Flush()
+	l.exit(1)
+}
+
+// fieldsPool holds the field slices of entries, for loggers that can reuse them.
+var fieldsPool = sync.Pool{
+	New: func() any {
+		fields := make([]Field, 0, 16)
+		return &fields
+	},
+}
+
+// logf builds an entry from the message, the logger's fields, the given fields and pairs,
+// then writes it, or queues it if the logger is asynchronous.
+// The template is the format string or the message that the message was built from.
+// It must be called directly by the exported logging methods, so that it finds their caller.
+func (l *Logger) logf(lvl Level, template, msg string, fields []Field, keysAndValues []any) {
+	e := Entry{
+		Time:     l.now(),
+		Level:    lvl,
+		Name:     l.name,
+		Message:  msg,
+		template: template,
+		Fields:   l.fields,
+	}
+
+	if len(fields) > 0 || len(keysAndValues) > 0 {
+		if l.reuseFields {
+			pooled := fieldsPool.Get().(*[]Field)
+			defer func() {
+				// don't keep the values alive
+				clear(e.Fields)
+				*pooled = e.Fields[:0]
+				fieldsPool.Put(pooled)
+			}()
+			e.Fields = append((*pooled)[:0], l.fields...)
+		} else {
+			e.Fields = make([]Field, len(l.fields), len(l.fields)+len(fields)+(len(keysAndValues)+1)/2)
+			copy(e.Fields, l.fields)
+		}
+
+		e.Fields = appendPairs(append(e.Fields, fields...), keysAndValues)
+	}
+
+	if l.caller {
+		var pcs [1]uintptr
+		// skip runtime.Callers, logf, and the logging method
+		runtime.Callers(3, pcs[:])
+		e.PC = pcs[0]
+	}
+
+	l.log(e)
+}
+
+// log applies the settings and the hooks of the logger to the entry, then dispatches it
+// unless a hook drops it or it is sampled out.
+func (l *Logger) log(e Entry) {
+	e, keep := l.process(e)
+	if !keep {
+		return
+	}
+
+	// fatal entries are never dropped, as they are the last words of the program
+	if l.sampler != nil && e.Level < LevelFatal {
+		summaries, keep := l.sampler.sample(e, l.now())
+		l.logSummaries(summaries)
+		if !keep {
+			return
+		}
+	}
+
+	l.dispatch(e)
+}
+
+// process applies the settings and the hooks of the logger to the entry,
+// and reports whether the hooks kept it.
+func (l *Logger) process(e Entry) (Entry, bool) {
+	e = l.prepare(e)
+
+	for _, h := range l.hooks {
+		if e.Level < h.threshold {
+			continue
+		}
+
+		var keep bool
+		if e, keep = h.hook.Process(e); !keep {
+			return e, false
+		}
+	}
+
+	return e, true
+}
+
+// logSummaries dispatches the summaries of sampled-out entries, which go through the settings and the hooks
+// of the logger like any other entry.
+func (l *Logger) logSummaries(summaries []Entry) {
+	for _, summary := range summaries {
+		if summary, keep := l.process(summary); keep {
+			l.dispatch(summary)
+		}
+	}
+}
+
+// dispatch writes the entry, or queues it if the logger is asynchronous.
+func (l *Logger) dispatch(e Entry) {
+	if l.queue != nil {
+		l.queue.push(e)
+		return
+	}
+
+	l.write(e)
+}
+
+// prepare sets the time zone of the entry, removes its secrets and truncates its texts,
+// as configured for the logger. Secrets are removed first, so that none is cut in a way
+// that the redactors no longer recognize.
+func (l *Logger) prepare(e Entry) Entry {
+	if l.utc {
+		e.Time = e.Time.UTC()
+	}
+	for _, r := range l.redactors {
+		e = r.Redact(e)
+	}
+	if l.maxLength > 0 {
+		e = truncateEntry(e, l.maxLength)
+	}
+	return e
+}
+
+// write hands the entry to each sink enabled for its level.
+// A failing sink doesn't prevent the others from receiving the entry.
+func (l *Logger) write(e Entry) {
+	for i, s := range l.sinks {
+		if !s.Enabled(e.Level) {
+			continue
+		}
+
+		if err := logTo(s, e); err != nil && l.onError != nil {
+			l.onError(fmt.Errorf("sink %d: %w", i, err))
+		}
+	}
+}
+
+// Flush waits until every entry queued by an asynchronous logger has been written,
+// then flushes the sinks that implement SinkFlusher.
+func (l *Logger) Flush() {
+	if l.queue != nil {
+		l.queue.flush()
+	}
+
+	for i, s := range l.sinks {
+		if f, ok := s.(SinkFlusher); ok {
+			if err := f.Flush(); err != nil && l.onError != nil {
+				l.onError(fmt.Errorf("sink %d: %w", i, err))
+			}
+		}
+	}
+}
+
+// closeClosers closes the sinks that implement io.Closer.
+func (l *Logger) closeClosers() {
+	for i, s := range l.sinks {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil && l.onError != nil {
+				l.onError(fmt.Errorf("sink %d: %w", i, err))
+			}
+		}
+	}
+}
+
+// Close stops the background goroutines of the logger, after it writes the summaries of sampled-out entries
+// and the entries queued by an asynchronous logger. Entries logged after Close are written synchronously.
+// Close then closes the sinks that implement io.Closer, such as NetworkSink, which sends its buffered entries.
+// Close does not close the output, except for the files opened by FromConfig. It is safe to call Close more than once.
+func (l *Logger) Close() {
+	if l.sampler != nil {
+		l.sampler.stop()
+		l.logSummaries(l.sampler.flush(l.now()))
+	}
+
+	if l.queue != nil {
+		l.queue.close()
+	}
+
+	l.closeSinks()
+
+	if l.closeOutputs != nil {
+		l.closeOutputs()
+	}
+}
+
+// Dropped returns the number of entries an asynchronous logger discarded because its queue was full.
+func (l *Logger) Dropped() uint64 {
+	if l.queue == nil {
+		return 0
+	}
+	return l.queue.droppedCount()
 }
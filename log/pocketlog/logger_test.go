@@ -49,6 +49,178 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 	}
 }
 
+func TestLogger_DebugwInfowErrorw(t *testing.T) {
+	type testCase struct {
+		keysAndValues []any
+		expected      string
+	}
+
+	tt := map[string]testCase{
+		"no fields": {
+			keysAndValues: nil,
+			expected:      "INFO:\t" + infoMessage + "\n",
+		},
+		"ordered fields": {
+			keysAndValues: []any{"request_id", "abc-123", "user_id", 42},
+			expected:      "INFO:\t" + infoMessage + " request_id=abc-123 user_id=42\n",
+		},
+		"quoted values": {
+			keysAndValues: []any{"empty", "", "spaces", "a b", "quote", `say "hi"`, "equal", "a=b", "newline", "a\nb"},
+			expected:      "INFO:\t" + infoMessage + ` empty="" spaces="a b" quote="say \"hi\"" equal="a=b" newline="a\nb"` + "\n",
+		},
+		"missing value": {
+			keysAndValues: []any{"user_id", 42, "dangling"},
+			expected:      "INFO:\t" + infoMessage + " user_id=42 !BADKEY=dangling\n",
+		},
+		"non-string key": {
+			keysAndValues: []any{42, "user_id", 42},
+			expected:      "INFO:\t" + infoMessage + " !BADKEY=42 user_id=42\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+			testedLogger.Debugw(debugMessage, tc.keysAndValues...)
+			testedLogger.Infow(infoMessage, tc.keysAndValues...)
+
+			if tw.contents != tc.expected {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_With(t *testing.T) {
+	tw := &testWriter{}
+
+	parent := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw)).With("request_id", "abc-123")
+	first := parent.With("user_id", 1)
+	second := parent.With("user_id", 2)
+
+	parent.Debugf(debugMessage)
+	first.Infof(infoMessage)
+	second.Errorw(errorMessage, "attempt", 3)
+
+	expected := "DEBUG:\t" + debugMessage + " request_id=abc-123\n" +
+		"INFO:\t" + infoMessage + " request_id=abc-123 user_id=1\n" +
+		"ERROR:\t" + errorMessage + " request_id=abc-123 user_id=2 attempt=3\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WarnfFatalf(t *testing.T) {
+	tw := &testWriter{}
+	exitCode := -1
+
+	testedLogger := pocketlog.New(pocketlog.LevelWarn, pocketlog.WithOutput(tw),
+		pocketlog.WithExitFunc(func(code int) { exitCode = code }))
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Warnf(debugMessage)
+	testedLogger.Warnw(infoMessage, "attempt", 2)
+	testedLogger.Fatalf(errorMessage)
+
+	expected := "WARN:\t" + debugMessage + "\n" + "WARN:\t" + infoMessage + " attempt=2\n" + "FATAL:\t" + errorMessage + "\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestLogger_Fatalw_Async(t *testing.T) {
+	tw := &testWriter{}
+	exited := false
+
+	testedLogger := pocketlog.New(pocketlog.LevelFatal, pocketlog.WithOutput(tw), pocketlog.WithAsync(10),
+		pocketlog.WithExitFunc(func(int) { exited = true }))
+	defer testedLogger.Close()
+
+	testedLogger.Errorf(errorMessage)
+	testedLogger.Fatalw(errorMessage, "code", 1)
+
+	// the entry must be written before the exit function is called
+	expected := "FATAL:\t" + errorMessage + " code=1\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+	if !exited {
+		t.Error("expected the exit function to be called")
+	}
+}
+
+func TestLogger_NamedLevels(t *testing.T) {
+	tw := &testWriter{}
+
+	root := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+	db := root.Named("db")
+	sql := db.Named("sql")
+	dbx := root.Named("dbx")
+
+	root.SetNamedLevel("db", pocketlog.LevelDebug)
+	sql.Debugf("query")
+	dbx.Debugf("hidden")
+	root.Debugf("hidden")
+
+	// the override of the closest name wins
+	root.SetNamedLevel("db.sql", pocketlog.LevelError)
+	sql.Infof("hidden")
+	db.Debugw("pool", "size", 4)
+
+	root.UnsetNamedLevel("db")
+	db.Debugf("hidden")
+
+	// the shared threshold changes for every logger without an override
+	root.With("ignored", true).SetLevel(pocketlog.LevelWarn)
+	root.Infof("hidden")
+	dbx.Warnf("slow")
+
+	expected := "DEBUG:\tdb.sql: query\n" + "DEBUG:\tdb: pool size=4\n" + "WARN:\tdbx: slow\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	if got := sql.Level(); got != pocketlog.LevelError {
+		t.Errorf("expected level %v for %q, got %v", pocketlog.LevelError, sql.Name(), got)
+	}
+	if got := db.Level(); got != pocketlog.LevelWarn {
+		t.Errorf("expected level %v for %q, got %v", pocketlog.LevelWarn, db.Name(), got)
+	}
+}
+
+func TestLogger_SetLevel_Named(t *testing.T) {
+	tw := &testWriter{}
+
+	root := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+	db := root.Named("db")
+	sql := db.Named("sql").With("pool", 1)
+	api := root.Named("api")
+
+	// a named logger only changes the threshold of its name and of its children
+	db.SetLevel(pocketlog.LevelDebug)
+	sql.Debugf("query")
+	api.Debugf("hidden")
+	root.Debugf("hidden")
+
+	if got := root.NamedLevels(); len(got) != 1 || got["db"] != pocketlog.LevelDebug {
+		t.Errorf("expected a single override for db, got %v", got)
+	}
+	if got := root.Level(); got != pocketlog.LevelInfo {
+		t.Errorf("expected the root level to stay %v, got %v", pocketlog.LevelInfo, got)
+	}
+
+	expected := "DEBUG:\tdb.sql: query pool=1\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
 // testWriter is a struct that implements io.Writer
 type testWriter struct {
 	contents string
@@ -0,0 +1,118 @@
+package pocketlog
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// Middleware returns an HTTP handler that calls next, then logs the request with its method, path,
+// status, number of bytes written, duration and remote address. Requests are logged at LevelError
+// for 5xx statuses, LevelWarn for 4xx statuses and LevelInfo otherwise.
+// During the request, FromContext(r.Context()) returns a child logger with the method and path of the request.
+//
+// If next panics, the middleware logs the panic and its stack trace at LevelError, and responds with
+// a 500 status; if the response had already started, it aborts it instead, as net/http does.
+// Panics with http.ErrAbortHandler aren't logged, as they abort the response on purpose.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := l.now()
+		lgr := l.WithFields(String("method", r.Method), String("path", r.URL.Path))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			aborted := false
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				lgr.Error("panic", Any("panic", v), String("stack", string(debug.Stack())))
+				if sw.wroteHeader {
+					aborted = true
+				} else {
+					http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}
+
+			fields := []Field{
+				Int("status", sw.status),
+				Int64("bytes", sw.bytes),
+				Duration("duration", l.now().Sub(start)),
+				String("remote", r.RemoteAddr),
+			}
+			switch {
+			case sw.status >= 500:
+				lgr.Error("request", fields...)
+			case sw.status >= 400:
+				lgr.Warn("request", fields...)
+			default:
+				lgr.Info("request", fields...)
+			}
+
+			if aborted {
+				panic(http.ErrAbortHandler)
+			}
+		}()
+
+		next.ServeHTTP(sw, r.WithContext(NewContext(r.Context(), lgr)))
+	})
+}
+
+// statusWriter records the status and the size of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader implements the http.ResponseWriter interface.
+func (sw *statusWriter) WriteHeader(code int) {
+	// informational statuses are followed by the final one
+	if !sw.wroteHeader && code >= 200 {
+		sw.status = code
+		sw.wroteHeader = true
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements the http.ResponseWriter interface.
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	sw.wroteHeader = true
+	n, err := sw.ResponseWriter.Write(p)
+	sw.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements the http.Flusher interface, if the underlying writer does.
+func (sw *statusWriter) Flush() {
+	sw.wroteHeader = true
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements the http.Hijacker interface, for handlers that take over the connection,
+// such as websocket upgrades. It returns an error that wraps http.ErrNotSupported
+// if the underlying writer can't be hijacked.
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: hijacking %T", http.ErrNotSupported, sw.ResponseWriter)
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		// the response can no longer be replaced after a panic
+		sw.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,190 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogtest"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_Middleware(t *testing.T) {
+	tt := map[string]struct {
+		status   int
+		body     string
+		expected pocketlog.Level
+	}{
+		"ok":           {status: http.StatusOK, body: "hello", expected: pocketlog.LevelInfo},
+		"redirect":     {status: http.StatusFound, expected: pocketlog.LevelInfo},
+		"client error": {status: http.StatusNotFound, body: "not found", expected: pocketlog.LevelWarn},
+		"server error": {status: http.StatusBadGateway, expected: pocketlog.LevelError},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			lgr, rec := pocketlogtest.NewLogger(t)
+
+			handler := lgr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pocketlog.FromContext(r.Context()).Info("handling")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			scoped := rec.Find(pocketlogtest.MessageContains("handling"),
+				pocketlogtest.HasField("method", "POST"), pocketlogtest.HasField("path", "/users"))
+			if len(scoped) != 1 {
+				t.Errorf("expected the handler to log with the request-scoped logger, got %v", rec.Entries())
+			}
+
+			count := rec.Count(pocketlogtest.AtLevel(tc.expected), pocketlogtest.MessageContains("request"),
+				pocketlogtest.HasField("method", "POST"), pocketlogtest.HasField("path", "/users"),
+				pocketlogtest.HasField("status", tc.status), pocketlogtest.HasField("bytes", int64(len(tc.body))),
+				pocketlogtest.HasKey("duration"), pocketlogtest.HasField("remote", "192.0.2.1:1234"))
+			if count != 1 {
+				t.Errorf("expected an access entry at level %s, got %v", tc.expected, rec.Entries())
+			}
+		})
+	}
+}
+
+func TestLogger_Middleware_Panic(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	handler := lgr.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("handler on fire")
+	}))
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.Code)
+	}
+
+	panics := rec.Find(pocketlogtest.AtLevel(pocketlog.LevelError), pocketlogtest.HasField("panic", "handler on fire"))
+	if len(panics) != 1 {
+		t.Fatalf("expected a panic entry, got %v", rec.Entries())
+	}
+	for _, f := range panics[0].Fields {
+		if f.Key == "stack" && !strings.Contains(f.Any().(string), "middleware_test.go") {
+			t.Errorf("expected a stack trace through the handler, got %s", f.Any())
+		}
+	}
+
+	if rec.Count(pocketlogtest.AtLevel(pocketlog.LevelError), pocketlogtest.HasField("status", 500)) != 1 {
+		t.Errorf("expected an access entry with status 500, got %v", rec.Entries())
+	}
+}
+
+func TestLogger_Middleware_Abort(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	tt := map[string]http.HandlerFunc{
+		"abort": func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		},
+		"panic after writing": func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("handler on fire")
+		},
+	}
+
+	for name, h := range tt {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if v := recover(); v != http.ErrAbortHandler {
+					t.Errorf("expected the response to be aborted, got %v", v)
+				}
+			}()
+
+			lgr.Middleware(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	}
+
+	if rec.Count(pocketlogtest.HasKey("panic")) != 1 {
+		t.Errorf("expected only the unexpected panic to be logged, got %v", rec.Entries())
+	}
+}
+
+func TestLogger_Middleware_Hijack(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	handler := lgr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("expected the writer to implement http.Hijacker")
+			return
+		}
+
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			if r.URL.Path != "/unsupported" || !errors.Is(err, http.ErrNotSupported) {
+				t.Errorf("unexpected error: %s", err)
+			}
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\nhello")
+		_ = rw.Flush()
+	}))
+
+	// a recorder can't be hijacked
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unsupported", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+
+	served := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(served)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n"); err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("unable to read response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+	// the body of a 101 response is the hijacked connection
+	if body, _ := io.ReadAll(br); string(body) != "hello" {
+		t.Errorf("expected the hijacked connection to carry %q, got %q", "hello", body)
+	}
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the handler to return")
+	}
+	if got := rec.Count(pocketlogtest.MessageContains("request"), pocketlogtest.HasField("path", "/ws")); got != 1 {
+		t.Errorf("expected the hijacked request to be logged, got %v", rec.Entries())
+	}
+}
@@ -0,0 +1,475 @@
+package pocketlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrSinkClosed is returned by sinks that receive entries after they were closed.
+const ErrSinkClosed = pocketlogError("sink closed")
+
+// ErrFlushTimeout is returned by NetworkSink.Flush when the buffered lines weren't sent in time.
+const ErrFlushTimeout = pocketlogError("flush timed out")
+
+const (
+	// defaultNetworkBufferSize is the default number of lines a NetworkSink holds while disconnected.
+	defaultNetworkBufferSize = 1024
+	// networkDialTimeout and networkWriteTimeout bound the time spent connecting and sending a line.
+	networkDialTimeout  = 5 * time.Second
+	networkWriteTimeout = 10 * time.Second
+	// networkStableConnection is how long a connection must stay up for the backoff to be reset.
+	networkStableConnection = 10 * time.Second
+	// defaultNetworkFlushTimeout is how long Flush waits for the buffered lines to be sent, by default.
+	defaultNetworkFlushTimeout = 5 * time.Second
+)
+
+// NetworkSink is a Sink that sends entries, one per line, to a TCP or unix socket address.
+// A background goroutine sends the lines, so that logging never waits for the network:
+// while disconnected, lines are buffered in memory, and optionally spilled to a file, until it
+// reconnects, waiting longer and longer between attempts. Flush waits for the buffered lines to be sent,
+// and Close sends them before closing the connection: Logger.Flush, Logger.Close and the Fatal methods
+// of the loggers the sink is attached to call them.
+// A NetworkSink is safe for concurrent use by multiple goroutines.
+type NetworkSink struct {
+	network   string
+	address   string
+	threshold Level
+	encoder   encoder
+
+	bufferSize   int
+	policy       OverflowPolicy
+	spillPath    string
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	flushTimeout time.Duration
+
+	// mu protects the fields below, and cond signals their changes.
+	mu   sync.Mutex
+	cond *sync.Cond
+	// lines are the lines waiting to be sent, oldest first.
+	lines [][]byte
+	// sending tells whether a line is being sent. It keeps its room in the buffer until it's written,
+	// so that it can be put back if the write fails.
+	sending bool
+	// spill holds the lines that didn't fit in memory, between spillOffset and spillEnd. While it holds
+	// some, new lines are spilled too, so that lines are sent in order.
+	spill       *os.File
+	spillOffset int64
+	spillEnd    int64
+	// conn is the current connection. It's reset when the server closes the connection.
+	conn    net.Conn
+	dropped uint64
+	closed  bool
+
+	// closing is closed by Close to stop waiting to reconnect, and done once the goroutine returns.
+	closing chan struct{}
+	done    chan struct{}
+}
+
+// NetworkOption defines a functional option to NewNetworkSink.
+type NetworkOption func(*NetworkSink)
+
+// WithEncoding returns a configuration function that sets how entries are encoded,
+// with the options that NewWriterSink accepts, such as WithFormat. Colors are only used with ColorAlways.
+func WithEncoding(opts ...EncodingOption) NetworkOption {
+	return func(s *NetworkSink) {
+		s.encoder = newEncoder(opts)
+	}
+}
+
+// WithBufferSize returns a configuration function that sets how many lines are held in memory
+// while disconnected. The default size is 1024 lines.
+func WithBufferSize(lines int) NetworkOption {
+	return func(s *NetworkSink) {
+		if lines > 0 {
+			s.bufferSize = lines
+		}
+	}
+}
+
+// WithDropPolicy returns a configuration function that sets what happens to a line when the buffer is full,
+// unless lines are spilled to a file. The default policy is OverflowDropNewest.
+// OverflowBlock makes the logger wait for room in the buffer.
+func WithDropPolicy(policy OverflowPolicy) NetworkOption {
+	return func(s *NetworkSink) {
+		s.policy = policy
+	}
+}
+
+// WithSpillFile returns a configuration function that writes the lines that don't fit in the buffer
+// to the file at path, instead of dropping them, and sends them once reconnected.
+// Close saves the lines it couldn't send to the file, and the next sink using the same file sends them first.
+func WithSpillFile(path string) NetworkOption {
+	return func(s *NetworkSink) {
+		s.spillPath = path
+	}
+}
+
+// WithBackoff returns a configuration function that sets how long the sink waits before reconnecting:
+// min after the first failure, then twice as long after each failure, up to max.
+// A connection that breaks within 10 seconds counts as a failure. The defaults are 100ms and 30s.
+func WithBackoff(min, max time.Duration) NetworkOption {
+	return func(s *NetworkSink) {
+		if min > 0 && max >= min {
+			s.minBackoff, s.maxBackoff = min, max
+		}
+	}
+}
+
+// WithFlushTimeout returns a configuration function that sets how long Flush waits for the buffered lines
+// to be sent. The default timeout is 5s.
+func WithFlushTimeout(timeout time.Duration) NetworkOption {
+	return func(s *NetworkSink) {
+		if timeout > 0 {
+			s.flushTimeout = timeout
+		}
+	}
+}
+
+// NewNetworkSink returns a sink that sends entries at or above threshold to address, over network,
+// which is "tcp", "tcp4", "tcp6" or "unix". It connects in the background: entries logged before it
+// is connected are buffered. It only fails to open the spill file.
+func NewNetworkSink(network, address string, threshold Level, opts ...NetworkOption) (*NetworkSink, error) {
+	s := &NetworkSink{
+		network:    network,
+		address:    address,
+		threshold:  threshold,
+		bufferSize: defaultNetworkBufferSize,
+		policy:     OverflowDropNewest,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+
+		flushTimeout: defaultNetworkFlushTimeout,
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	for _, configFunc := range opts {
+		configFunc(s)
+	}
+	// network outputs are never terminals
+	s.encoder.color = useColor(s.encoder.colorMode, false, os.Getenv("NO_COLOR"))
+
+	if s.spillPath != "" {
+		f, err := os.OpenFile(s.spillPath, os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open spill file: %w", err)
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("unable to open spill file: %w", err)
+		}
+		// lines left by a previous sink are sent first
+		s.spill, s.spillEnd = f, info.Size()
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+// Enabled implements the Sink interface.
+func (s *NetworkSink) Enabled(lvl Level) bool {
+	return lvl >= s.threshold
+}
+
+// Log implements the Sink interface. It buffers the line of the entry, applying the drop policy
+// if the buffer is full.
+func (s *NetworkSink) Log(e Entry) error {
+	line := s.encoder.encode(nil, e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// without a spill file, apply the policy
+	for s.spill == nil && s.full() && !s.closed {
+		switch s.policy {
+		case OverflowDropNewest:
+			s.dropped++
+			return nil
+		case OverflowDropOldest:
+			if len(s.lines) == 0 {
+				// the only line is being sent, and can't be dropped anymore
+				s.dropped++
+				return nil
+			}
+			s.lines[0] = nil
+			s.lines = s.lines[1:]
+			s.dropped++
+		default:
+			s.cond.Wait()
+		}
+	}
+
+	if s.closed {
+		return ErrSinkClosed
+	}
+
+	if s.spillOffset < s.spillEnd || s.full() {
+		if _, err := s.spill.WriteAt(line, s.spillEnd); err != nil {
+			s.dropped++
+			return fmt.Errorf("unable to spill entry: %w", err)
+		}
+		s.spillEnd += int64(len(line))
+		s.cond.Broadcast()
+		return nil
+	}
+
+	s.lines = append(s.lines, line)
+	s.cond.Broadcast()
+	return nil
+}
+
+// Flush implements the SinkFlusher interface. It waits until every buffered line was sent, including
+// the spilled ones, or until the flush timeout, in which case it returns an error that wraps ErrFlushTimeout.
+// It returns immediately once the sink is closed.
+func (s *NetworkSink) Flush() error {
+	timedOut := false
+	timer := time.AfterFunc(s.flushTimeout, func() {
+		s.mu.Lock()
+		timedOut = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for (len(s.lines) > 0 || s.sending || s.spillOffset < s.spillEnd) && !s.closed {
+		if timedOut {
+			return fmt.Errorf("%w: %d lines in memory, %d bytes spilled", ErrFlushTimeout, len(s.lines), s.spillEnd-s.spillOffset)
+		}
+		s.cond.Wait()
+	}
+	return nil
+}
+
+// full reports whether the buffer has no room for another line. It must be called with mu held.
+func (s *NetworkSink) full() bool {
+	n := len(s.lines)
+	if s.sending {
+		n++
+	}
+	return n >= s.bufferSize
+}
+
+// Dropped returns the number of lines the sink discarded because its buffer was full,
+// or because it was closed before it could send them.
+func (s *NetworkSink) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropped
+}
+
+// Close sends the buffered lines if the sink is connected, then closes the connection.
+// The lines it can't send are saved to the spill file, if any, or dropped.
+// It is safe to call Close more than once.
+func (s *NetworkSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.closing)
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.spill == nil {
+		s.dropped += uint64(len(s.lines))
+		s.lines = nil
+		return nil
+	}
+
+	err := s.saveSpill()
+	return errors.Join(err, s.spill.Close())
+}
+
+// run connects to the address and sends lines until the sink is closed, waiting longer and longer
+// between failed attempts. A connection that the server drops before it was stable counts as a failure,
+// so that a server that accepts connections and closes them right away isn't redialed in a loop.
+func (s *NetworkSink) run() {
+	defer close(s.done)
+
+	backoff := s.minBackoff
+	for {
+		stable := false
+		if conn, err := net.DialTimeout(s.network, s.address, networkDialTimeout); err == nil {
+			connected := time.Now()
+			s.send(conn)
+			stable = time.Since(connected) >= networkStableConnection
+		}
+
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			// either every line was sent, or the connection was lost while closing
+			return
+		}
+
+		if stable {
+			backoff = s.minBackoff
+			continue
+		}
+
+		select {
+		case <-s.closing:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, s.maxBackoff)
+	}
+}
+
+// send sends lines over the connection until it breaks, or until the sink is closed and every line was sent.
+func (s *NetworkSink) send(conn net.Conn) {
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	// servers don't write to log collectors: a read returns once the server closes the connection,
+	// which lets the sink reconnect before it loses a line to a dead connection
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+			s.cond.Broadcast()
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		s.mu.Lock()
+		line := s.next(conn)
+		s.mu.Unlock()
+		if line == nil {
+			return
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+		_, err := conn.Write(line)
+
+		s.mu.Lock()
+		s.sending = false
+		if err != nil {
+			// send the line again once reconnected, in the room it kept
+			s.lines = append([][]byte{line}, s.lines...)
+		} else {
+			// there is room for blocked loggers
+			s.cond.Broadcast()
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// next takes the next line to send out of the buffer, waiting for one, and marks it as being sent.
+// It returns nil once the connection broke, or once the sink is closed and has no more lines.
+// It must be called with mu held.
+func (s *NetworkSink) next(conn net.Conn) []byte {
+	for {
+		if s.conn != conn {
+			return nil
+		}
+
+		if len(s.lines) == 0 && s.spillOffset < s.spillEnd {
+			s.refill()
+		}
+
+		if len(s.lines) > 0 {
+			line := s.lines[0]
+			s.lines[0] = nil
+			s.lines = s.lines[1:]
+			s.sending = true
+			return line
+		}
+
+		if s.closed {
+			return nil
+		}
+		s.cond.Wait()
+	}
+}
+
+// refill moves up to a buffer of lines from the spill file to memory, and empties the file
+// once every line was read. It must be called with mu held.
+func (s *NetworkSink) refill() {
+	r := bufio.NewReader(io.NewSectionReader(s.spill, s.spillOffset, s.spillEnd-s.spillOffset))
+	for !s.full() {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			s.lines = append(s.lines, line)
+			s.spillOffset += int64(len(line))
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				// the rest of the file can't be read
+				s.spillOffset = s.spillEnd
+			}
+			break
+		}
+	}
+
+	if s.spillOffset >= s.spillEnd {
+		s.spillOffset, s.spillEnd = 0, 0
+		_ = s.spill.Truncate(0)
+	}
+}
+
+// saveSpill rewrites the spill file with the lines in memory, followed by the lines still spilled,
+// for the next sink to send them. It must be called with mu held.
+func (s *NetworkSink) saveSpill() error {
+	if len(s.lines) == 0 && s.spillOffset == 0 {
+		return nil
+	}
+
+	spilled := make([]byte, s.spillEnd-s.spillOffset)
+	if _, err := s.spill.ReadAt(spilled, s.spillOffset); err != nil {
+		return fmt.Errorf("unable to save unsent entries: %w", err)
+	}
+
+	var data []byte
+	for _, line := range s.lines {
+		data = append(data, line...)
+	}
+	data = append(data, spilled...)
+
+	if _, err := s.spill.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("unable to save unsent entries: %w", err)
+	}
+	s.lines = nil
+	s.spillOffset, s.spillEnd = 0, int64(len(data))
+	return s.spill.Truncate(s.spillEnd)
+}
@@ -0,0 +1,312 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/pocketlog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// netTestBackoff makes network sinks reconnect quickly in tests.
+var netTestBackoff = pocketlog.WithBackoff(time.Millisecond, 10*time.Millisecond)
+
+func TestNetworkSink_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer listener.Close()
+
+	sink, err := pocketlog.NewNetworkSink("tcp", listener.Addr().String(), pocketlog.LevelInfo, netTestBackoff,
+		pocketlog.WithEncoding(pocketlog.WithFormat(pocketlog.FormatLogfmt)))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink), pocketlog.WithClock(func() time.Time { return time.Time{} }))
+	lgr.Infof("first")
+	lgr.Debugf(debugMessage)
+
+	conn := accept(t, listener)
+	expectLines(t, conn, "level=info msg=first")
+
+	// the server goes away, and the sink reconnects without losing entries
+	conn.Close()
+	conn = accept(t, listener)
+	defer conn.Close()
+
+	lgr.Infof("second")
+	lgr.Errorf("third")
+	expectLines(t, conn, "level=info msg=second", "level=error msg=third")
+
+	if err := sink.Close(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if dropped := sink.Dropped(); dropped != 0 {
+		t.Errorf("expected no dropped entries, got %d", dropped)
+	}
+
+	if err := sink.Log(pocketlog.Entry{}); err != pocketlog.ErrSinkClosed {
+		t.Errorf("expected ErrSinkClosed after Close, got %v", err)
+	}
+}
+
+func TestNetworkSink_Flush(t *testing.T) {
+	path := socketPath(t)
+
+	sink, err := pocketlog.NewNetworkSink("unix", path, pocketlog.LevelInfo, netTestBackoff,
+		pocketlog.WithFlushTimeout(20*time.Millisecond), pocketlog.WithEncoding(pocketlog.WithFormat(pocketlog.FormatLogfmt)))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink), pocketlog.WithClock(func() time.Time { return time.Time{} }))
+	lgr.Infof("first")
+
+	// the server is down
+	if err := sink.Flush(); !errors.Is(err, pocketlog.ErrFlushTimeout) {
+		t.Errorf("expected ErrFlushTimeout, got %v", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer listener.Close()
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conns <- conn
+		}
+	}()
+
+	// Flush only returns nil once the lines were written to the connection
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.Flush() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the sink to connect")
+		}
+	}
+	lgr.Errorf("second")
+	if err := sink.Flush(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	conn := <-conns
+	defer conn.Close()
+	expectLines(t, conn, "level=info msg=first", "level=error msg=second")
+}
+
+func TestNetworkSink_Backoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer listener.Close()
+
+	// the server drops every connection right away
+	accepted := make(chan time.Time, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+			select {
+			case accepted <- time.Now():
+			default:
+			}
+		}
+	}()
+
+	sink, err := pocketlog.NewNetworkSink("tcp", listener.Addr().String(), pocketlog.LevelInfo,
+		pocketlog.WithBackoff(20*time.Millisecond, 40*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	// the sink only redials once the connection is closed, and waits longer each time, up to the maximum
+	last := <-accepted
+	for _, wait := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond} {
+		var next time.Time
+		select {
+		case next = <-accepted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the sink to reconnect")
+		}
+
+		if gap := next.Sub(last); gap < wait {
+			t.Errorf("expected the sink to wait %s before reconnecting, got %s", wait, gap)
+		}
+		last = next
+	}
+}
+
+func TestNetworkSink_DropPolicy(t *testing.T) {
+	tt := map[string]struct {
+		policy   pocketlog.OverflowPolicy
+		expected []string
+	}{
+		"drop newest": {policy: pocketlog.OverflowDropNewest, expected: []string{"level=info msg=0", "level=info msg=1"}},
+		"drop oldest": {policy: pocketlog.OverflowDropOldest, expected: []string{"level=info msg=2", "level=info msg=3"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			path := socketPath(t)
+
+			// the server is down
+			sink, err := pocketlog.NewNetworkSink("unix", path, pocketlog.LevelInfo, netTestBackoff,
+				pocketlog.WithBufferSize(2), pocketlog.WithDropPolicy(tc.policy),
+				pocketlog.WithEncoding(pocketlog.WithFormat(pocketlog.FormatLogfmt)))
+			if err != nil {
+				t.Fatalf("unable to create sink: %s", err)
+			}
+			defer sink.Close()
+
+			lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink), pocketlog.WithClock(func() time.Time { return time.Time{} }))
+			for i := 0; i < 4; i++ {
+				lgr.Infof("%d", i)
+			}
+
+			if dropped := sink.Dropped(); dropped != 2 {
+				t.Errorf("expected 2 dropped entries, got %d", dropped)
+			}
+
+			// the server comes back
+			listener, err := net.Listen("unix", path)
+			if err != nil {
+				t.Fatalf("unable to listen: %s", err)
+			}
+			defer listener.Close()
+
+			conn := accept(t, listener)
+			defer conn.Close()
+			expectLines(t, conn, tc.expected...)
+		})
+	}
+}
+
+func TestNetworkSink_Spill(t *testing.T) {
+	path := socketPath(t)
+	spillPath := filepath.Join(t.TempDir(), "spill.log")
+	opts := []pocketlog.NetworkOption{
+		netTestBackoff, pocketlog.WithBufferSize(1), pocketlog.WithSpillFile(spillPath),
+		pocketlog.WithEncoding(pocketlog.WithFormat(pocketlog.FormatLogfmt)),
+	}
+
+	// the first sink never connects: Close saves its entries in the spill file
+	sink, err := pocketlog.NewNetworkSink("unix", path, pocketlog.LevelInfo, opts...)
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink), pocketlog.WithClock(func() time.Time { return time.Time{} }))
+	for i := 0; i < 3; i++ {
+		lgr.Infof("%d", i)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	contents, _ := os.ReadFile(spillPath)
+	if expected := "level=info msg=0\nlevel=info msg=1\nlevel=info msg=2\n"; string(contents) != expected {
+		t.Fatalf("invalid spill file, expected %q, got %q", expected, contents)
+	}
+
+	// the second sink sends them first, then the entries it spilled itself
+	sink, err = pocketlog.NewNetworkSink("unix", path, pocketlog.LevelInfo, opts...)
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+	lgr = pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink), pocketlog.WithClock(func() time.Time { return time.Time{} }))
+	for i := 3; i < 6; i++ {
+		lgr.Infof("%d", i)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer listener.Close()
+
+	conn := accept(t, listener)
+	defer conn.Close()
+
+	expected := make([]string, 6)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("level=info msg=%d", i)
+	}
+	expectLines(t, conn, expected...)
+
+	if dropped := sink.Dropped(); dropped != 0 {
+		t.Errorf("expected no dropped entries, got %d", dropped)
+	}
+}
+
+// socketPath returns the path of a unix socket in a new directory.
+// Socket paths are limited to about 100 bytes, which t.TempDir can exceed.
+func socketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "netsink")
+	if err != nil {
+		t.Fatalf("unable to create directory: %s", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "socket")
+}
+
+// accept waits for the next connection to the listener.
+func accept(t *testing.T, listener net.Listener) net.Conn {
+	t.Helper()
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conns <- conn
+		}
+		close(conns)
+	}()
+
+	select {
+	case conn, ok := <-conns:
+		if !ok {
+			t.Fatalf("unable to accept a connection")
+		}
+		return conn
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a connection")
+		return nil
+	}
+}
+
+// expectLines reads lines from the connection, and checks them.
+func expectLines(t *testing.T, conn net.Conn, expected ...string) {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, want := range expected {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unable to read line %q: %s", want, err)
+		}
+		if got := line[:len(line)-1]; got != want {
+			t.Errorf("expected line %q, got %q", want, got)
+		}
+	}
+}
@@ -1,13 +1,203 @@
 package pocketlog
 
-import "io"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
 
 // Option defines a functional option to our logger
-type Option func(*Logger)
+type Option interface {
+	apply(lgr *Logger)
+}
+
+// optionFunc is an Option that changes the logger.
+type optionFunc func(*Logger)
+
+// apply implements the Option interface.
+func (f optionFunc) apply(lgr *Logger) {
+	f(lgr)
+}
+
+// EncodingOption is an Option that sets how entries are encoded.
+// NewWriterSink and WithEncoding only accept these options.
+type EncodingOption func(*encoder)
+
+// apply implements the Option interface.
+func (f EncodingOption) apply(lgr *Logger) {
+	f(&lgr.encoder)
+}
 
 // WithOutput returns a configuration function that sets the output of logs.
+// The output is ignored once sinks are attached with WithSink.
 func WithOutput(output io.Writer) Option {
-	return func(lgr *Logger) {
+	return optionFunc(func(lgr *Logger) {
 		lgr.output = output
+	})
+}
+
+// WithSlogHandler returns a configuration function that attaches a sink handing entries
+// to a log/slog handler. Fields become slog attributes.
+func WithSlogHandler(handler slog.Handler) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.sinks = append(lgr.sinks, slogSink{handler: handler})
+	})
+}
+
+// WithSink returns a configuration function that attaches sinks to the logger.
+// Every entry that passes the threshold of the logger is handed to each sink that is enabled
+// for its level. Once a sink is attached, the output of the logger is no longer used.
+func WithSink(sinks ...Sink) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.sinks = append(lgr.sinks, sinks...)
+	})
+}
+
+// WithErrorHandler returns a configuration function that sets the function called with
+// the errors returned by sinks. It is called from the goroutine that writes the entry, which is
+// the background goroutine of asynchronous loggers. Errors are ignored by default.
+func WithErrorHandler(handler func(err error)) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.onError = handler
+	})
+}
+
+// WithFormat returns a configuration function that sets the format of logs.
+// The default format is FormatText.
+func WithFormat(format Format) EncodingOption {
+	return func(enc *encoder) {
+		enc.format = format
+	}
+}
+
+// WithAsync returns a configuration function that makes the logger queue up to bufferSize entries
+// and write them from a background goroutine. Call Close before exiting to write queued entries.
+// A bufferSize of zero or less keeps the logger synchronous.
+func WithAsync(bufferSize int) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.queueSize = bufferSize
+	})
+}
+
+// WithOverflowPolicy returns a configuration function that sets what an asynchronous logger does
+// when its queue is full. The default policy is OverflowBlock. Fatal entries always wait for room.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.overflowPolicy = policy
+	})
+}
+
+// WithExitFunc returns a configuration function that sets the function called by Fatalf and Fatalw
+// once their message is written. The default exit function is os.Exit.
+func WithExitFunc(exit func(code int)) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.exit = exit
+	})
+}
+
+// WithTimestamp returns a configuration function that starts text entries with their time,
+// in the given layout. An empty layout stands for time.RFC3339.
+// JSON entries always have a time, in the time.RFC3339Nano layout.
+func WithTimestamp(layout string) EncodingOption {
+	return func(enc *encoder) {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		enc.timeLayout = layout
 	}
 }
+
+// WithUTC returns a configuration function that prints times in UTC instead of the local time zone.
+func WithUTC() Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.utc = true
+	})
+}
+
+// WithCaller returns a configuration function that adds the file and line of the code that logged
+// each entry, as in pocketlog/logger.go:42.
+func WithCaller() Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.caller = true
+	})
+}
+
+// WithErrorStack returns a configuration function that makes Err add the stack trace of its caller
+// to the entries of the child logger it returns.
+func WithErrorStack() Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.errorStack = true
+	})
+}
+
+// WithPrefix returns a configuration function that starts every text entry with prefix.
+func WithPrefix(prefix string) EncodingOption {
+	return func(enc *encoder) {
+		enc.prefix = prefix
+	}
+}
+
+// WithClock returns a configuration function that sets the function returning the time of entries.
+// The default clock is time.Now.
+func WithClock(now func() time.Time) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.now = now
+	})
+}
+
+// WithMaxMessageLength returns a configuration function that cuts messages and text field values
+// longer than maxBytes bytes, without splitting a UTF-8 character. Cut texts end with a marker
+// that tells how many bytes were dropped, such as "...[truncated 42 bytes]".
+func WithMaxMessageLength(maxBytes int) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.maxLength = maxBytes
+	})
+}
+
+// WithSampling returns a configuration function that limits repeated entries. In every interval,
+// the first entries of each template are logged, then only one out of every thereafter entries;
+// a thereafter of zero or less drops all the others. A template is the format string of the *f
+// methods, or the message of the *w methods. Fatal entries are never dropped.
+// At the end of every interval, a summary such as "suppressed 42 similar messages" is logged for each
+// template with dropped entries, through the redactors and hooks. Close logs the last summaries.
+func WithSampling(first, thereafter int, interval time.Duration) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.sampler = newSampler(first, thereafter, interval)
+	})
+}
+
+// WithColor returns a configuration function that sets whether the level of text entries is colored.
+// The default mode, ColorAuto, only colors entries written to a terminal, unless the NO_COLOR
+// environment variable is set. Entries written anywhere else are not changed.
+func WithColor(mode ColorMode) EncodingOption {
+	return func(enc *encoder) {
+		enc.colorMode = mode
+	}
+}
+
+// WithRedactor returns a configuration function that adds redactors to the logger.
+// Redactors run in order on every entry that passes the threshold, before sinks receive it.
+func WithRedactor(redactors ...Redactor) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.redactors = append(lgr.redactors, redactors...)
+	})
+}
+
+// WithHook returns a configuration function that adds a hook that runs on every entry at or above threshold,
+// after the redactors. Hooks run in the order they were added, and an entry dropped by a hook
+// doesn't reach the next hooks nor the sinks.
+func WithHook(threshold Level, hook Hook) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.hooks = append(lgr.hooks, levelHook{threshold: threshold, hook: hook})
+	})
+}
+
+// WithContextExtractor returns a configuration function that adds a function returning fields
+// held by a context, such as the IDs set by a tracing library. The *Ctx methods add these fields
+// after the ones added with ContextWithFields.
+func WithContextExtractor(extract func(ctx context.Context) []Field) Option {
+	return optionFunc(func(lgr *Logger) {
+		lgr.extractors = append(lgr.extractors, extract)
+	})
+}
@@ -0,0 +1,94 @@
+package pocketlogquery
+
+import (
+	"encoding/json"
+	"log/pocketlog"
+	"regexp"
+	"time"
+)
+
+// Filter tells whether a record is of interest.
+type Filter func(r Record) bool
+
+// All returns a filter that matches the records that match all the filters.
+func All(filters ...Filter) Filter {
+	return func(r Record) bool {
+		for _, filter := range filters {
+			if !filter(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AtLeast returns a filter that matches the records at or above the level.
+func AtLeast(lvl pocketlog.Level) Filter {
+	return func(r Record) bool {
+		return r.Level >= lvl
+	}
+}
+
+// Between returns a filter that matches the records logged at or after since, and before until.
+// A zero time leaves its side of the range open. Records without a time never match a bounded range.
+func Between(since, until time.Time) Filter {
+	return func(r Record) bool {
+		if !since.IsZero() && (r.Time.IsZero() || r.Time.Before(since)) {
+			return false
+		}
+		if !until.IsZero() && (r.Time.IsZero() || !r.Time.Before(until)) {
+			return false
+		}
+		return true
+	}
+}
+
+// FieldEquals returns a filter that matches the records with a field whose text is the value.
+// The text of strings and numbers is their value, and the text of other values is their JSON encoding.
+// The name and the message of records can be matched with the keys "logger" and "message".
+func FieldEquals(key, value string) Filter {
+	return func(r Record) bool {
+		text, ok := r.text(key)
+		return ok && text == value
+	}
+}
+
+// FieldMatches returns a filter that matches the records with a field whose text matches
+// the regular expression. It matches texts the same way as FieldEquals.
+func FieldMatches(key string, re *regexp.Regexp) Filter {
+	return func(r Record) bool {
+		text, ok := r.text(key)
+		return ok && re.MatchString(text)
+	}
+}
+
+// text returns the text of the field of the record with the key.
+func (r Record) text(key string) (string, bool) {
+	for _, f := range r.Fields {
+		if f.Key == key {
+			return valueText(f.Any()), true
+		}
+	}
+
+	switch key {
+	case "logger":
+		return r.Name, r.Name != ""
+	case "message":
+		return r.Message, true
+	default:
+		return "", false
+	}
+}
+
+// valueText returns the text of a field value.
+func valueText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
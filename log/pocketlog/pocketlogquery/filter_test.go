@@ -0,0 +1,61 @@
+package pocketlogquery_test
+
+import (
+	"encoding/json"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogquery"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec := pocketlogquery.Record{Entry: pocketlog.Entry{
+		Time:    now,
+		Level:   pocketlog.LevelWarn,
+		Name:    "http",
+		Message: "slow request",
+		Fields: []pocketlog.Field{
+			pocketlog.Any("path", "/users"),
+			pocketlog.Any("ms", json.Number("1500")),
+			pocketlog.Any("tags", []any{"a"}),
+		},
+	}}
+
+	tt := map[string]struct {
+		filter   pocketlogquery.Filter
+		expected bool
+	}{
+		"at least lower level":   {filter: pocketlogquery.AtLeast(pocketlog.LevelInfo), expected: true},
+		"at least higher level":  {filter: pocketlogquery.AtLeast(pocketlog.LevelError), expected: false},
+		"in range":               {filter: pocketlogquery.Between(now, now.Add(time.Second)), expected: true},
+		"open range":             {filter: pocketlogquery.Between(time.Time{}, time.Time{}), expected: true},
+		"before range":           {filter: pocketlogquery.Between(now.Add(time.Second), time.Time{}), expected: false},
+		"range excludes until":   {filter: pocketlogquery.Between(time.Time{}, now), expected: false},
+		"string field equals":    {filter: pocketlogquery.FieldEquals("path", "/users"), expected: true},
+		"number field equals":    {filter: pocketlogquery.FieldEquals("ms", "1500"), expected: true},
+		"array field equals":     {filter: pocketlogquery.FieldEquals("tags", `["a"]`), expected: true},
+		"field differs":          {filter: pocketlogquery.FieldEquals("path", "/"), expected: false},
+		"missing field":          {filter: pocketlogquery.FieldEquals("user", ""), expected: false},
+		"logger equals":          {filter: pocketlogquery.FieldEquals("logger", "http"), expected: true},
+		"field matches":          {filter: pocketlogquery.FieldMatches("ms", regexp.MustCompile(`^1\d{3}$`)), expected: true},
+		"message does not match": {filter: pocketlogquery.FieldMatches("message", regexp.MustCompile(`^fast`)), expected: false},
+		"all match": {
+			filter:   pocketlogquery.All(pocketlogquery.AtLeast(pocketlog.LevelWarn), pocketlogquery.FieldEquals("path", "/users")),
+			expected: true,
+		},
+		"not all match": {
+			filter:   pocketlogquery.All(pocketlogquery.AtLeast(pocketlog.LevelWarn), pocketlogquery.FieldEquals("path", "/")),
+			expected: false,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.filter(rec); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package pocketlogquery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// maxLineLength is the length of the longest line a Reader accepts.
+const maxLineLength = 1 << 20
+
+// Reader reads records from JSON lines.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader returns a Reader of the JSON lines of r.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next record. It returns io.EOF at the end of the input, and an error that wraps
+// ErrInvalidRecord, with the number of the line, for lines that aren't records.
+// Reading can go on after invalid records. Empty lines are skipped.
+func (r *Reader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		rec, err := Parse(line)
+		if err != nil {
+			return Record{}, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// follower is a reader of a file that waits for more data at its end.
+type follower struct {
+	ctx      context.Context
+	path     string
+	interval time.Duration
+
+	file   *os.File
+	offset int64
+}
+
+// Follow opens the file at path and returns a reader of the data appended to it, that waits for more
+// data at the end of the file instead of returning io.EOF. Reading starts after the last complete line
+// of the file, and the data is checked for every interval. The reader reopens the file when the file
+// is replaced, as when it's rotated, and reads it again from the beginning when it's truncated.
+// Reads return io.EOF once ctx is done.
+func Follow(ctx context.Context, path string, interval time.Duration) (io.ReadCloser, error) {
+	return follow(ctx, path, interval, endOfLastLine)
+}
+
+// FollowFromStart is like Follow, but reading starts at the beginning of the file,
+// so that the existing lines are read before the new ones.
+func FollowFromStart(ctx context.Context, path string, interval time.Duration) (io.ReadCloser, error) {
+	return follow(ctx, path, interval, func(*os.File) (int64, error) { return 0, nil })
+}
+
+// follow opens the file at path and returns a reader of its data from the offset that start returns.
+func follow(ctx context.Context, path string, interval time.Duration, start func(f *os.File) (int64, error)) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := start(f)
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &follower{ctx: ctx, path: path, interval: interval, file: f, offset: offset}, nil
+}
+
+// endOfLastLine returns the offset that follows the last newline of the file, or 0 if it has none,
+// so that a line that is still being written is read whole.
+func endOfLastLine(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Read implements the io.Reader interface.
+func (fl *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := fl.file.Read(p)
+		fl.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if err := fl.reopen(); err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-fl.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(fl.interval):
+		}
+	}
+}
+
+// reopen opens the file at the path again if it was replaced, and rewinds it if it was truncated.
+// It's called once the current file is read to its end.
+func (fl *follower) reopen() error {
+	info, err := os.Stat(fl.path)
+	if err != nil {
+		// the file may be between its rotation and the creation of the new one
+		return nil
+	}
+
+	current, err := fl.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, current) {
+		f, err := os.Open(fl.path)
+		if err != nil {
+			return nil
+		}
+		_ = fl.file.Close()
+		fl.file, fl.offset = f, 0
+		return nil
+	}
+
+	if info.Size() < fl.offset {
+		if _, err := fl.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		fl.offset = 0
+	}
+	return nil
+}
+
+// Close implements the io.Closer interface.
+func (fl *follower) Close() error {
+	return fl.file.Close()
+}
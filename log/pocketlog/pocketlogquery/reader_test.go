@@ -0,0 +1,145 @@
+package pocketlogquery_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/pocketlog/pocketlogquery"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	input := `{"level":"info","message":"first"}
+
+not a record
+{"level":"error","message":"second"}
+`
+	reader := pocketlogquery.NewReader(strings.NewReader(input))
+
+	rec, err := reader.Next()
+	if err != nil || rec.Message != "first" {
+		t.Fatalf("expected the first record, got %+v, %v", rec, err)
+	}
+
+	_, err = reader.Next()
+	if !errors.Is(err, pocketlogquery.ErrInvalidRecord) || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Fatalf("expected an invalid record on line 3, got %v", err)
+	}
+
+	rec, err = reader.Next()
+	if err != nil || rec.Message != "second" {
+		t.Fatalf("expected the second record, got %+v, %v", rec, err)
+	}
+
+	if _, err = reader.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// existing entries are skipped, and the last line is still being written
+	writeFile(t, path, strings.Repeat(`{"level":"info","message":"old"}`+"\n", 200)+`{"level":"info",`, os.O_CREATE|os.O_WRONLY)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, err := pocketlogquery.Follow(ctx, path, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unable to follow: %s", err)
+	}
+	defer r.Close()
+
+	records := make(chan string)
+	go func() {
+		defer close(records)
+		reader := pocketlogquery.NewReader(r)
+		for {
+			rec, err := reader.Next()
+			if err != nil {
+				return
+			}
+			records <- rec.Message
+		}
+	}()
+
+	writeFile(t, path, `"message":"first"}`+"\n", os.O_APPEND|os.O_WRONLY)
+	expectRecord(t, records, "first")
+
+	// appended
+	writeFile(t, path, `{"level":"info","message":"second"}`+"\n", os.O_APPEND|os.O_WRONLY)
+	expectRecord(t, records, "second")
+
+	// rotated
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("unable to rotate: %s", err)
+	}
+	writeFile(t, path, `{"level":"info","message":"third"}`+"\n", os.O_CREATE|os.O_WRONLY)
+	expectRecord(t, records, "third")
+
+	// truncated
+	writeFile(t, path, `{"level":"info","message":"4"}`+"\n", os.O_TRUNC|os.O_WRONLY)
+	expectRecord(t, records, "4")
+
+	cancel()
+	if _, ok := <-records; ok {
+		t.Errorf("expected the end of the records")
+	}
+}
+
+func TestFollowFromStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeFile(t, path, `{"level":"info","message":"old"}`+"\n", os.O_CREATE|os.O_WRONLY)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, err := pocketlogquery.FollowFromStart(ctx, path, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unable to follow: %s", err)
+	}
+	defer r.Close()
+
+	reader := pocketlogquery.NewReader(r)
+	if rec, err := reader.Next(); err != nil || rec.Message != "old" {
+		t.Fatalf("expected the existing record, got %+v, %v", rec, err)
+	}
+
+	writeFile(t, path, `{"level":"info","message":"new"}`+"\n", os.O_APPEND|os.O_WRONLY)
+	if rec, err := reader.Next(); err != nil || rec.Message != "new" {
+		t.Fatalf("expected the appended record, got %+v, %v", rec, err)
+	}
+}
+
+// writeFile writes the contents to the file at path, opened with the flags.
+func writeFile(t *testing.T, path, contents string, flag int) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		t.Fatalf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+}
+
+// expectRecord waits for the next record, and checks its message.
+func expectRecord(t *testing.T, records <-chan string, expected string) {
+	t.Helper()
+
+	select {
+	case got := <-records:
+		if got != expected {
+			t.Fatalf("expected record %q, got %q", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for record %q", expected)
+	}
+}
@@ -0,0 +1,113 @@
+// Package pocketlogquery reads back the JSON lines written by pocketlog loggers, and filters them.
+package pocketlogquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/pocketlog"
+	"time"
+)
+
+// queryError defines a sentinel error
+type queryError string
+
+// Error is the implementation of the error interface by queryError
+func (e queryError) Error() string {
+	return string(e)
+}
+
+// ErrInvalidRecord is returned for lines that aren't JSON entries written by pocketlog.
+const ErrInvalidRecord = queryError("invalid record")
+
+// Record is an entry read back from its JSON representation.
+type Record struct {
+	pocketlog.Entry
+	// Caller is the file and line that logged the entry, as in dir/file.go:12. It's empty
+	// unless the logger recorded callers. The PC of the entry is always zero.
+	Caller string
+}
+
+// jsonRecord is the JSON representation of an entry, as written by pocketlog.
+type jsonRecord struct {
+	Time    string          `json:"time"`
+	Level   string          `json:"level"`
+	Logger  string          `json:"logger"`
+	Caller  string          `json:"caller"`
+	Message string          `json:"message"`
+	Fields  json.RawMessage `json:"fields"`
+}
+
+// Parse returns the record of a JSON line. Fields keep their order. Numbers are decoded as
+// json.Number, and objects and arrays as map[string]any and []any.
+func Parse(line []byte) (Record, error) {
+	var jr jsonRecord
+	if err := json.Unmarshal(line, &jr); err != nil {
+		return Record{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	lvl, err := pocketlog.ParseLevel(jr.Level)
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	r := Record{
+		Entry: pocketlog.Entry{
+			Level:   lvl,
+			Name:    jr.Logger,
+			Message: jr.Message,
+		},
+		Caller: jr.Caller,
+	}
+
+	if jr.Time != "" {
+		r.Time, err = time.Parse(time.RFC3339Nano, jr.Time)
+		if err != nil {
+			return Record{}, fmt.Errorf("%w: invalid time: %s", ErrInvalidRecord, err)
+		}
+	}
+
+	if len(jr.Fields) != 0 {
+		r.Fields, err = parseFields(jr.Fields)
+		if err != nil {
+			return Record{}, fmt.Errorf("%w: invalid fields: %s", ErrInvalidRecord, err)
+		}
+	}
+
+	return r, nil
+}
+
+// parseFields decodes a JSON object into fields, in the order of its keys.
+func parseFields(data []byte) ([]pocketlog.Field, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		// "fields": null
+		return nil, nil
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object, got %v", tok)
+	}
+
+	var fields []pocketlog.Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// keys of objects are always strings
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, pocketlog.Any(tok.(string), value))
+	}
+
+	return fields, nil
+}
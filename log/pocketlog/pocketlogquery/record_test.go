@@ -0,0 +1,62 @@
+package pocketlogquery_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogquery"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(&buf), pocketlog.WithFormat(pocketlog.FormatJSON),
+		pocketlog.WithClock(func() time.Time { return now }), pocketlog.WithCaller())
+	lgr.Named("http").Warnw("slow request", "path", "/", "ms", 1500, "tags", []string{"a"}, "ok", false)
+
+	got, err := pocketlogquery.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !got.Time.Equal(now) || got.Level != pocketlog.LevelWarn || got.Name != "http" || got.Message != "slow request" {
+		t.Errorf("invalid record %+v", got)
+	}
+	if !strings.HasPrefix(got.Caller, "pocketlogquery/record_test.go:") {
+		t.Errorf("invalid caller %q", got.Caller)
+	}
+
+	expected := []pocketlog.Field{
+		pocketlog.Any("path", "/"),
+		pocketlog.Any("ms", json.Number("1500")),
+		pocketlog.Any("tags", []any{"a"}),
+		pocketlog.Any("ok", false),
+	}
+	if !reflect.DeepEqual(got.Fields, expected) {
+		t.Errorf("invalid fields, expected %v, got %v", expected, got.Fields)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tt := map[string]string{
+		"not JSON":      `level=info`,
+		"unknown level": `{"level":"loud","message":"m"}`,
+		"invalid time":  `{"time":"yesterday","level":"info","message":"m"}`,
+		"invalid field": `{"level":"info","message":"m","fields":[1]}`,
+	}
+
+	for name, line := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := pocketlogquery.Parse([]byte(line))
+			if !errors.Is(err, pocketlogquery.ErrInvalidRecord) {
+				t.Errorf("expected ErrInvalidRecord, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package pocketlogtest provides a sink that records entries in memory,
+// so that tests can check what their code logged.
+package pocketlogtest
+
+import (
+	"bytes"
+	"log/pocketlog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Recorder is a pocketlog.Sink that keeps every entry it receives in memory.
+// A Recorder is safe for concurrent use by multiple goroutines.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []pocketlog.Entry
+}
+
+// NewRecorder returns a recorder that prints its entries with t.Log at the end of the test, if it failed.
+func NewRecorder(t testing.TB) *Recorder {
+	r := &Recorder{}
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			r.dump(t)
+		}
+	})
+
+	return r
+}
+
+// NewLogger returns a logger at LevelDebug that hands its entries to a new recorder,
+// with the given options, and the recorder.
+func NewLogger(t testing.TB, opts ...pocketlog.Option) (*pocketlog.Logger, *Recorder) {
+	r := NewRecorder(t)
+	lgr := pocketlog.New(pocketlog.LevelDebug, append(opts, pocketlog.WithSink(r))...)
+	return lgr, r
+}
+
+// Enabled implements the pocketlog.Sink interface. A recorder keeps entries of every level.
+func (r *Recorder) Enabled(pocketlog.Level) bool {
+	return true
+}
+
+// Log implements the pocketlog.Sink interface.
+func (r *Recorder) Log(e pocketlog.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// Entries returns a copy of the recorded entries, in the order they were logged.
+func (r *Recorder) Entries() []pocketlog.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]pocketlog.Entry(nil), r.entries...)
+}
+
+// Find returns the recorded entries that match all the queries, in the order they were logged.
+func (r *Recorder) Find(queries ...Query) []pocketlog.Entry {
+	var found []pocketlog.Entry
+	for _, e := range r.Entries() {
+		if matchAll(e, queries) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Count returns the number of recorded entries that match all the queries.
+func (r *Recorder) Count(queries ...Query) int {
+	return len(r.Find(queries...))
+}
+
+// Reset forgets the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// dump prints the recorded entries as text, one call to t.Log per entry.
+func (r *Recorder) dump(t testing.TB) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	sink := pocketlog.NewWriterSink(buf, pocketlog.LevelDebug, pocketlog.WithColor(pocketlog.ColorNever))
+
+	entries := r.Entries()
+	t.Logf("%d entries were logged:", len(entries))
+	for _, e := range entries {
+		buf.Reset()
+		_ = sink.Log(e)
+		t.Log(strings.TrimSuffix(buf.String(), "\n"))
+	}
+}
+
+// Query tells whether an entry is of interest.
+type Query func(e pocketlog.Entry) bool
+
+// AtLevel returns a query that matches entries logged at the given level.
+func AtLevel(lvl pocketlog.Level) Query {
+	return func(e pocketlog.Entry) bool {
+		return e.Level == lvl
+	}
+}
+
+// MessageContains returns a query that matches entries whose message contains substr.
+func MessageContains(substr string) Query {
+	return func(e pocketlog.Entry) bool {
+		return strings.Contains(e.Message, substr)
+	}
+}
+
+// HasField returns a query that matches entries with a field of the given key and value.
+// Values are compared with reflect.DeepEqual, so the type of value matters: 42 doesn't match int64(42).
+func HasField(key string, value any) Query {
+	return func(e pocketlog.Entry) bool {
+		for _, f := range e.Fields {
+			if f.Key == key && reflect.DeepEqual(f.Any(), value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasKey returns a query that matches entries with a field of the given key, whatever its value.
+func HasKey(key string) Query {
+	return func(e pocketlog.Entry) bool {
+		for _, f := range e.Fields {
+			if f.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchAll reports whether the entry matches every query.
+func matchAll(e pocketlog.Entry, queries []Query) bool {
+	for _, q := range queries {
+		if !q(e) {
+			return false
+		}
+	}
+	return true
+}
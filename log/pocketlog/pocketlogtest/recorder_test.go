@@ -0,0 +1,117 @@
+package pocketlogtest_test
+
+import (
+	"errors"
+	"fmt"
+	"log/pocketlog"
+	"log/pocketlog/pocketlogtest"
+	"testing"
+)
+
+func TestRecorder_Find(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t)
+
+	lgr.Debugf("connecting to %s", "db")
+	lgr.With("request_id", "abc-123").Infow("request served", "status", 200)
+	lgr.Errorw("request failed", "status", 500, "err", errors.New("timeout"))
+
+	tt := map[string]struct {
+		queries  []pocketlogtest.Query
+		expected []string
+	}{
+		"no query": {
+			queries:  nil,
+			expected: []string{"connecting to db", "request served", "request failed"},
+		},
+		"level": {
+			queries:  []pocketlogtest.Query{pocketlogtest.AtLevel(pocketlog.LevelError)},
+			expected: []string{"request failed"},
+		},
+		"message": {
+			queries:  []pocketlogtest.Query{pocketlogtest.MessageContains("request")},
+			expected: []string{"request served", "request failed"},
+		},
+		"field": {
+			queries:  []pocketlogtest.Query{pocketlogtest.HasField("status", 200)},
+			expected: []string{"request served"},
+		},
+		"logger field": {
+			queries:  []pocketlogtest.Query{pocketlogtest.HasKey("request_id")},
+			expected: []string{"request served"},
+		},
+		"all queries": {
+			queries:  []pocketlogtest.Query{pocketlogtest.MessageContains("request"), pocketlogtest.AtLevel(pocketlog.LevelInfo), pocketlogtest.HasField("status", 500)},
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			found := rec.Find(tc.queries...)
+
+			if len(found) != len(tc.expected) {
+				t.Fatalf("expected %d entries, got %d: %v", len(tc.expected), len(found), found)
+			}
+			for i, e := range found {
+				if e.Message != tc.expected[i] {
+					t.Errorf("expected message %q, got %q", tc.expected[i], e.Message)
+				}
+			}
+		})
+	}
+
+	rec.Reset()
+	if got := rec.Count(); got != 0 {
+		t.Errorf("expected no entries after Reset, got %d", got)
+	}
+}
+
+// fakeTB records what a test would print, and whether it failed.
+type fakeTB struct {
+	testing.TB
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper()          {}
+func (tb *fakeTB) Failed() bool     { return tb.failed }
+func (tb *fakeTB) Cleanup(f func()) { tb.cleanups = append(tb.cleanups, f) }
+func (tb *fakeTB) Log(args ...any)  { tb.logs = append(tb.logs, fmt.Sprint(args...)) }
+func (tb *fakeTB) Logf(format string, args ...any) {
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+func TestNewRecorder_Dump(t *testing.T) {
+	tt := map[string]struct {
+		failed   bool
+		expected []string
+	}{
+		"passed": {
+			failed:   false,
+			expected: nil,
+		},
+		"failed": {
+			failed:   true,
+			expected: []string{"2 entries were logged:", "INFO:\tstarting port=8080", "ERROR:\tdb: unreachable"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tb := &fakeTB{failed: tc.failed}
+			lgr, _ := pocketlogtest.NewLogger(tb)
+
+			lgr.Infow("starting", "port", 8080)
+			lgr.Named("db").Errorf("unreachable")
+
+			for _, cleanup := range tb.cleanups {
+				cleanup()
+			}
+
+			if fmt.Sprint(tb.logs) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected logs %q, got %q", tc.expected, tb.logs)
+			}
+		})
+	}
+}
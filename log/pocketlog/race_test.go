@@ -0,0 +1,8 @@
+//go:build race
+
+package pocketlog_test
+
+func init() {
+	// the race detector allocates
+	raceEnabled = true
+}
@@ -0,0 +1,85 @@
+package pocketlog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the secrets removed by the built-in redactors.
+const Redacted = "[REDACTED]"
+
+// Redactor removes secrets from entries before sinks receive them.
+type Redactor interface {
+	// Redact returns the entry without its secrets. The fields of the entry may be shared
+	// with the logger: they must be copied before being changed. Values are read with Field.Any,
+	// and replaced with new fields, such as String(f.Key, "[REDACTED]").
+	Redact(e Entry) Entry
+}
+
+// RedactorFunc is an adapter to use an ordinary function as a Redactor.
+type RedactorFunc func(e Entry) Entry
+
+// Redact implements the Redactor interface.
+func (f RedactorFunc) Redact(e Entry) Entry {
+	return f(e)
+}
+
+// regexRedactor replaces the matches of a regular expression in messages and text field values.
+type regexRedactor struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// NewRegexRedactor returns a redactor that replaces the matches of re with replacement, in messages
+// and in field values made of text: strings, byte slices, errors and fmt.Stringers.
+// The replacement may refer to submatches, as in regexp.Regexp.ReplaceAllString.
+func NewRegexRedactor(re *regexp.Regexp, replacement string) Redactor {
+	return regexRedactor{re: re, replacement: replacement}
+}
+
+// Redact implements the Redactor interface.
+func (r regexRedactor) Redact(e Entry) Entry {
+	e.Message = r.re.ReplaceAllString(e.Message, r.replacement)
+	e.Fields = replaceValues(e.Fields, func(f Field) (any, bool) {
+		text, ok := f.textValue()
+		if !ok || !r.re.MatchString(text) {
+			return nil, false
+		}
+		return r.re.ReplaceAllString(text, r.replacement), true
+	})
+
+	return e
+}
+
+// keyRedactor replaces the values of fields with sensitive keys.
+type keyRedactor struct {
+	keys map[string]struct{}
+}
+
+// NewKeyRedactor returns a redactor that replaces the value of fields with one of the given keys
+// with Redacted. Keys are compared ignoring case, and also match the last part of the keys of
+// fields in slog groups: the "password" key matches the "user.password" field.
+func NewKeyRedactor(keys ...string) Redactor {
+	r := keyRedactor{keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+	return r
+}
+
+// Redact implements the Redactor interface.
+func (r keyRedactor) Redact(e Entry) Entry {
+	e.Fields = replaceValues(e.Fields, func(f Field) (any, bool) {
+		key := strings.ToLower(f.Key)
+		if i := strings.LastIndex(key, nameSeparator); i >= 0 {
+			key = key[i+1:]
+		}
+
+		if _, ok := r.keys[key]; !ok {
+			return nil, false
+		}
+		return Redacted, true
+	})
+
+	return e
+}
@@ -0,0 +1,100 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"log/pocketlog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithRedactor(t *testing.T) {
+	bearer := pocketlog.NewRegexRedactor(regexp.MustCompile(`(Bearer )[A-Za-z0-9._-]+`), "${1}"+pocketlog.Redacted)
+	keys := pocketlog.NewKeyRedactor("password", "API_KEY")
+
+	tt := map[string]struct {
+		format   pocketlog.Format
+		log      func(lgr *pocketlog.Logger)
+		expected string
+	}{
+		"formatted message": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infof("calling with header %q", "Bearer abc.def-123")
+			},
+			expected: "INFO:\tcalling with header \"Bearer [REDACTED]\"\n",
+		},
+		"field values": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infow("login", "user", "bob", "password", "hunter2", "err", errors.New("bad token Bearer xyz"))
+			},
+			expected: "INFO:\tlogin user=bob password=[REDACTED] err=\"bad token Bearer [REDACTED]\"\n",
+		},
+		"logger fields and case-insensitive keys": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With("api_key", "s3cr3t", "request.Password", 1234).Infof("ready")
+			},
+			expected: "INFO:\tready api_key=[REDACTED] request.Password=[REDACTED]\n",
+		},
+		"json": {
+			format: pocketlog.FormatJSON,
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infow("auth Bearer abc", "password", "hunter2")
+			},
+			expected: `"message":"auth Bearer [REDACTED]","fields":{"password":"[REDACTED]"}}` + "\n",
+		},
+		"custom redactor": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Errorw("card declined", "card", "4111111111111111")
+			},
+			expected: "ERROR:\tcard declined card=************1111\n",
+		},
+		"custom redactor with a typed field": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Error("card declined", pocketlog.String("card", "4111111111111111"))
+			},
+			expected: "ERROR:\tcard declined card=************1111\n",
+		},
+	}
+
+	card := pocketlog.RedactorFunc(func(e pocketlog.Entry) pocketlog.Entry {
+		fields := make([]pocketlog.Field, len(e.Fields))
+		for i, f := range e.Fields {
+			if s, ok := f.Any().(string); ok && f.Key == "card" && len(s) > 4 {
+				f = pocketlog.String(f.Key, strings.Repeat("*", len(s)-4)+s[len(s)-4:])
+			}
+			fields[i] = f
+		}
+		e.Fields = fields
+		return e
+	})
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(tc.format),
+				pocketlog.WithRedactor(bearer, keys, card))
+
+			tc.log(testedLogger)
+
+			if !strings.HasSuffix(tw.contents, tc.expected) {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_WithRedactor_BeforeTruncation(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithMaxMessageLength(12),
+		pocketlog.WithRedactor(pocketlog.NewRegexRedactor(regexp.MustCompile(`token=\w+`), "token=***")))
+
+	// truncating first would leave "token=abcdef" in the output
+	testedLogger.Infof("token=abcdefghijkl")
+
+	const expected = "INFO:\ttoken=***\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
@@ -0,0 +1,343 @@
+package pocketlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeLayout is the layout of the timestamp appended to the name of rotated files.
+// It sorts lexically in chronological order.
+const backupTimeLayout = "20060102T150405.000000000"
+
+// compressedSuffix is appended to the name of rotated files once they are compressed.
+const compressedSuffix = ".gz"
+
+// RotatingFile is an io.WriteCloser that appends to a file, and moves it aside once it grows
+// too big or gets too old. Rotated files are named after the file, followed by the rotation time.
+// A RotatingFile is safe for concurrent use by multiple goroutines.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+	reopenOn   []os.Signal
+	now        func() time.Time
+
+	// mu protects the fields below, and is held during rotations.
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+
+	// cleanups tracks the goroutines that compress and remove rotated files.
+	cleanups sync.WaitGroup
+	// cleanupMu prevents cleanups from running at the same time.
+	cleanupMu sync.Mutex
+
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// RotateOption defines a functional option to a RotatingFile
+type RotateOption func(*RotatingFile)
+
+// WithMaxSize returns a configuration function that rotates the file before a write
+// would make it larger than maxBytes. A file is never rotated while it is empty.
+func WithMaxSize(maxBytes int64) RotateOption {
+	return func(rf *RotatingFile) {
+		rf.maxSize = maxBytes
+	}
+}
+
+// WithRotationInterval returns a configuration function that rotates the file every interval.
+// Rotations happen on the first write after a multiple of the interval, counted from the zero time,
+// so an interval of 24 hours rotates the file after midnight UTC.
+func WithRotationInterval(interval time.Duration) RotateOption {
+	return func(rf *RotatingFile) {
+		rf.interval = interval
+	}
+}
+
+// WithMaxBackups returns a configuration function that sets how many rotated files are kept.
+// Older files are removed. The default, zero, keeps every rotated file.
+func WithMaxBackups(n int) RotateOption {
+	return func(rf *RotatingFile) {
+		rf.maxBackups = n
+	}
+}
+
+// WithCompression returns a configuration function that gzips rotated files in the background.
+func WithCompression() RotateOption {
+	return func(rf *RotatingFile) {
+		rf.compress = true
+	}
+}
+
+// WithReopenOnSIGHUP returns a configuration function that reopens the file when the process
+// receives SIGHUP, so that tools like logrotate can move the file away.
+func WithReopenOnSIGHUP() RotateOption {
+	return func(rf *RotatingFile) {
+		rf.reopenOn = append(rf.reopenOn, syscall.SIGHUP)
+	}
+}
+
+// NewRotatingFile opens the file at path for appending, creating it and its directory if needed.
+func NewRotatingFile(path string, opts ...RotateOption) (*RotatingFile, error) {
+	rf := &RotatingFile{path: path, now: time.Now}
+
+	for _, configFunc := range opts {
+		configFunc(rf)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create the directory of %q: %w", path, err)
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	if len(rf.reopenOn) > 0 {
+		rf.signals = make(chan os.Signal, 1)
+		rf.done = make(chan struct{})
+		signal.Notify(rf.signals, rf.reopenOn...)
+		go rf.watchSignals()
+	}
+
+	return rf, nil
+}
+
+// Write implements the io.Writer interface. It rotates the file first, if needed.
+func (rf *RotatingFile) Write(p []byte) (n int, err error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if rf.shouldRotate(len(p)) {
+		if err = rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Rotate moves the current file aside and starts a new one, whatever its size and age.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return os.ErrClosed
+	}
+	return rf.rotate()
+}
+
+// Reopen closes the file and opens the file at the same path again.
+// Use it once the file was moved by another program.
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return os.ErrClosed
+	}
+
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("unable to close %q: %w", rf.path, err)
+	}
+	return rf.open()
+}
+
+// Close closes the file, and waits for rotated files to be compressed and removed.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	if rf.file == nil {
+		rf.mu.Unlock()
+		return os.ErrClosed
+	}
+
+	err := rf.file.Close()
+	rf.file = nil
+	rf.mu.Unlock()
+
+	if rf.signals != nil {
+		signal.Stop(rf.signals)
+		close(rf.done)
+	}
+
+	rf.cleanups.Wait()
+	return err
+}
+
+// watchSignals reopens the file every time one of the signals is received, until the file is closed.
+func (rf *RotatingFile) watchSignals() {
+	for {
+		select {
+		case <-rf.signals:
+			// there is no one to report the error to: the next write will fail if the file is unusable
+			_ = rf.Reopen()
+		case <-rf.done:
+			return
+		}
+	}
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+// It must be called with mu held.
+func (rf *RotatingFile) shouldRotate(n int) bool {
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(n) > rf.maxSize {
+		return true
+	}
+
+	return rf.interval > 0 && !rf.now().Before(rf.nextRotation)
+}
+
+// open opens the file at path and resets the size and the rotation time.
+// It must be called with mu held.
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open %q for writing: %w", rf.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("unable to read the size of %q: %w", rf.path, err)
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	if rf.interval > 0 {
+		rf.nextRotation = rf.now().Truncate(rf.interval).Add(rf.interval)
+	}
+
+	return nil
+}
+
+// rotate renames the file after the current time, opens a new one,
+// and starts cleaning up rotated files in the background.
+// It must be called with mu held.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("unable to close %q: %w", rf.path, err)
+	}
+
+	backup := rf.path + "." + rf.now().Format(backupTimeLayout)
+	if err := os.Rename(rf.path, backup); err != nil {
+		// keep writing to the same file rather than losing entries
+		if openErr := rf.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("unable to rename %q: %w", rf.path, err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	rf.cleanups.Add(1)
+	go func() {
+		defer rf.cleanups.Done()
+		rf.cleanup(backup)
+	}()
+
+	return nil
+}
+
+// cleanup compresses the rotated file if needed, then removes the oldest rotated files.
+// Errors are ignored: a file that can't be compressed or removed is left as is.
+func (rf *RotatingFile) cleanup(backup string) {
+	rf.cleanupMu.Lock()
+	defer rf.cleanupMu.Unlock()
+
+	if rf.compress {
+		_ = compressFile(backup)
+	}
+
+	if rf.maxBackups <= 0 {
+		return
+	}
+
+	backups, err := rf.backups()
+	if err != nil || len(backups) <= rf.maxBackups {
+		return
+	}
+
+	for _, name := range backups[rf.maxBackups:] {
+		_ = os.Remove(name)
+	}
+}
+
+// backups returns the rotated files, newest first.
+func (rf *RotatingFile) backups() ([]string, error) {
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]string, 0, len(matches))
+	for _, name := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, rf.path+"."), compressedSuffix)
+		if _, err = time.Parse(backupTimeLayout, stamp); err == nil {
+			backups = append(backups, name)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// compressFile replaces the file at path with a gzipped copy named after it.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// write to a temporary name, so that a half-compressed file is never taken for a backup
+	tmp := path + compressedSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp, path+compressedSuffix); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
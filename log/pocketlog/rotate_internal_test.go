@@ -0,0 +1,49 @@
+package pocketlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2022, 11, 7, 23, 59, 0, 0, time.UTC)
+
+	rf, err := NewRotatingFile(path, WithRotationInterval(24*time.Hour), func(rf *RotatingFile) {
+		rf.now = func() time.Time { return now }
+	})
+	if err != nil {
+		t.Fatalf("unable to open rotating file: %s", err)
+	}
+	defer rf.Close()
+
+	_, _ = rf.Write([]byte("monday\n"))
+
+	// still the same day: no rotation
+	now = now.Add(30 * time.Second)
+	_, _ = rf.Write([]byte("still monday\n"))
+
+	// past midnight: rotate before writing
+	now = now.Add(time.Minute)
+	_, _ = rf.Write([]byte("tuesday\n"))
+
+	backup := path + "." + now.Format(backupTimeLayout)
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("expected a backup named %q: %s", backup, err)
+	}
+	if string(data) != "monday\nstill monday\n" {
+		t.Errorf("expected backup to hold %q, got %q", "monday\nstill monday\n", data)
+	}
+
+	data, _ = os.ReadFile(path)
+	if string(data) != "tuesday\n" {
+		t.Errorf("expected current file to hold %q, got %q", "tuesday\n", data)
+	}
+
+	if expected := time.Date(2022, 11, 9, 0, 0, 0, 0, time.UTC); !rf.nextRotation.Equal(expected) {
+		t.Errorf("expected next rotation at %s, got %s", expected, rf.nextRotation)
+	}
+}
@@ -0,0 +1,223 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"log/pocketlog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_MaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	rf, err := pocketlog.NewRotatingFile(path, pocketlog.WithMaxSize(10), pocketlog.WithMaxBackups(2))
+	if err != nil {
+		t.Fatalf("unable to open rotating file: %s", err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err = rf.Write([]byte(line)); err != nil {
+			t.Fatalf("unable to write: %s", err)
+		}
+	}
+
+	if err = rf.Close(); err != nil {
+		t.Fatalf("unable to close: %s", err)
+	}
+
+	// every line overflows the previous one, and the first backup was removed
+	if got := readFile(t, path); got != "fourth\n" {
+		t.Errorf("expected current file to hold %q, got %q", "fourth\n", got)
+	}
+
+	backups := backupFiles(t, path)
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", backups)
+	}
+	if got := readFile(t, backups[0]) + readFile(t, backups[1]); got != "second\nthird\n" {
+		t.Errorf("expected backups to hold %q, got %q", "second\nthird\n", got)
+	}
+}
+
+func TestRotatingFile_Compression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	rf, err := pocketlog.NewRotatingFile(path, pocketlog.WithCompression())
+	if err != nil {
+		t.Fatalf("unable to open rotating file: %s", err)
+	}
+
+	_, _ = rf.Write([]byte("compress me\n"))
+	if err = rf.Rotate(); err != nil {
+		t.Fatalf("unable to rotate: %s", err)
+	}
+	_ = rf.Close()
+
+	backups := backupFiles(t, path)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("expected a single gzipped backup, got %v", backups)
+	}
+
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatalf("unable to open backup: %s", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("unable to read gzip header: %s", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("unable to decompress: %s", err)
+	}
+	if string(data) != "compress me\n" {
+		t.Errorf("expected %q, got %q", "compress me\n", data)
+	}
+}
+
+func TestRotatingFile_ReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := pocketlog.NewRotatingFile(path, pocketlog.WithReopenOnSIGHUP())
+	if err != nil {
+		t.Fatalf("unable to open rotating file: %s", err)
+	}
+	defer rf.Close()
+
+	_, _ = rf.Write([]byte("before\n"))
+
+	// this is what logrotate does before signalling the process
+	moved := filepath.Join(dir, "app.log.1")
+	if err = os.Rename(path, moved); err != nil {
+		t.Fatalf("unable to move file: %s", err)
+	}
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unable to find own process: %s", err)
+	}
+	if err = proc.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("unable to send SIGHUP: %s", err)
+	}
+
+	// the file is reopened asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err = os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file was not reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, _ = rf.Write([]byte("after\n"))
+
+	if got := readFile(t, moved); got != "before\n" {
+		t.Errorf("expected moved file to hold %q, got %q", "before\n", got)
+	}
+	if got := readFile(t, path); got != "after\n" {
+		t.Errorf("expected new file to hold %q, got %q", "after\n", got)
+	}
+}
+
+func TestRotatingFile_ConcurrentLogging(t *testing.T) {
+	const (
+		goroutines = 20
+		entries    = 100
+	)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	rf, err := pocketlog.NewRotatingFile(path, pocketlog.WithMaxSize(512), pocketlog.WithCompression())
+	if err != nil {
+		t.Fatalf("unable to open rotating file: %s", err)
+	}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(rf))
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				testedLogger.Infow(infoMessage, "goroutine", g, "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if err = rf.Close(); err != nil {
+		t.Fatalf("unable to close: %s", err)
+	}
+
+	lines := countLines(t, path, false)
+	for _, backup := range backupFiles(t, path) {
+		lines += countLines(t, backup, true)
+	}
+
+	if lines != goroutines*entries {
+		t.Errorf("expected %d lines across all files, got %d", goroutines*entries, lines)
+	}
+}
+
+// backupFiles returns the rotated files of path.
+func backupFiles(t *testing.T, path string) []string {
+	t.Helper()
+
+	backups, err := filepath.Glob(path + ".2*")
+	if err != nil {
+		t.Fatalf("unable to list backups: %s", err)
+	}
+	return backups
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %q: %s", path, err)
+	}
+	return string(data)
+}
+
+// countLines counts the complete lines of an entry in the file, failing on any partial line.
+func countLines(t *testing.T, path string, gzipped bool) int {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unable to open %q: %s", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		if r, err = gzip.NewReader(f); err != nil {
+			t.Fatalf("unable to read gzip header of %q: %s", path, err)
+		}
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if !strings.HasPrefix(scanner.Text(), "INFO:\t"+infoMessage) {
+			t.Fatalf("unexpected line %q in %q", scanner.Text(), path)
+		}
+		lines++
+	}
+	return lines
+}
@@ -0,0 +1,153 @@
+package pocketlog
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sampleKey groups similar entries: the same template, logged at the same level by loggers with the same name.
+type sampleKey struct {
+	level    Level
+	name     string
+	template string
+}
+
+// sampler lets through the first entries of each template in an interval, then one entry out of every few.
+// It is shared by a logger and all of its children, and is safe for concurrent use.
+type sampler struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+
+	mu sync.Mutex
+	// windowEnd is the end of the current interval.
+	windowEnd time.Time
+	// seen counts the entries of each template logged during the current interval.
+	seen map[sampleKey]int
+	// suppressed counts the entries of each template dropped during the current interval.
+	suppressed map[sampleKey]int
+
+	// done is closed to stop the goroutine that emits summaries, which closes exited when it returns.
+	done     chan struct{}
+	exited   chan struct{}
+	stopOnce sync.Once
+}
+
+// newSampler returns a sampler that lets through the first entries of each template per interval,
+// then every thereafter-th entry. A thereafter of zero or less drops every other entry of the interval.
+func newSampler(first, thereafter int, interval time.Duration) *sampler {
+	return &sampler{
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		seen:       make(map[sampleKey]int),
+		suppressed: make(map[sampleKey]int),
+	}
+}
+
+// sample reports whether the entry should be logged. When now is past the current interval,
+// it also returns the summaries of the entries dropped during that interval.
+func (s *sampler) sample(e Entry, now time.Time) (summaries []Entry, keep bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !now.Before(s.windowEnd) {
+		summaries = s.rollover(now)
+		s.windowEnd = now.Add(s.interval)
+	}
+
+	key := sampleKey{level: e.Level, name: e.Name, template: e.template}
+	s.seen[key]++
+
+	n := s.seen[key]
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return summaries, true
+	}
+
+	s.suppressed[key]++
+	return summaries, false
+}
+
+// start calls emit at the end of every interval, from a new goroutine, until stop is called.
+// Summaries are thus logged even when no entry follows a flood. It does nothing for intervals
+// that aren't positive.
+func (s *sampler) start(emit func()) {
+	if s.interval <= 0 {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.exited = make(chan struct{})
+	go func() {
+		defer close(s.exited)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				emit()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops the goroutine started by start, and waits for it to return. It is safe to call stop more than once.
+func (s *sampler) stop() {
+	if s.done == nil {
+		return
+	}
+
+	s.stopOnce.Do(func() { close(s.done) })
+	<-s.exited
+}
+
+// flush returns the summaries of the entries dropped so far, and starts a new interval.
+func (s *sampler) flush(now time.Time) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := s.rollover(now)
+	s.windowEnd = now.Add(s.interval)
+	return summaries
+}
+
+// rollover returns one summary entry per template with dropped entries, and resets the counters.
+// Summaries are sorted by level, name and template, so that they always come in the same order.
+// It must be called with mu held.
+func (s *sampler) rollover(now time.Time) []Entry {
+	keys := make([]sampleKey, 0, len(s.suppressed))
+	for key := range s.suppressed {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return keys[i].level < keys[j].level
+		}
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].template < keys[j].template
+	})
+
+	summaries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		count := s.suppressed[key]
+		summaries = append(summaries, Entry{
+			Time:     now,
+			Level:    key.level,
+			Name:     key.name,
+			Message:  "suppressed " + strconv.Itoa(count) + " similar messages",
+			template: key.template,
+			Fields:   []Field{Any("template", key.template), Any("suppressed", count)},
+		})
+	}
+
+	s.seen = make(map[sampleKey]int)
+	s.suppressed = make(map[sampleKey]int)
+	return summaries
+}
@@ -0,0 +1,96 @@
+package pocketlog_test
+
+import (
+	"log/pocketlog"
+	"log/pocketlog/pocketlogtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_WithSampling(t *testing.T) {
+	tw := &testWriter{}
+	now := time.Date(2022, 11, 7, 10, 30, 0, 0, time.UTC)
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw),
+		pocketlog.WithClock(func() time.Time { return now }), pocketlog.WithSampling(2, 3, time.Second))
+	defer testedLogger.Close()
+
+	for i := 1; i <= 10; i++ {
+		testedLogger.Errorf("connection refused: attempt %d", i)
+	}
+	// other templates and levels are counted separately
+	testedLogger.Infof("connection refused: attempt %d", 1)
+	testedLogger.Named("db").Errorf("connection refused: attempt %d", 1)
+
+	expected := "ERROR:\tconnection refused: attempt 1\n" +
+		"ERROR:\tconnection refused: attempt 2\n" +
+		"ERROR:\tconnection refused: attempt 5\n" +
+		"ERROR:\tconnection refused: attempt 8\n" +
+		"INFO:\tconnection refused: attempt 1\n" +
+		"ERROR:\tdb: connection refused: attempt 1\n"
+	if tw.contents != expected {
+		t.Fatalf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+
+	// the next interval starts with a summary of the previous one
+	tw.contents = ""
+	now = now.Add(time.Second)
+	testedLogger.Errorw("connection refused")
+
+	expected = "ERROR:\tsuppressed 6 similar messages template=\"connection refused: attempt %d\" suppressed=6\n" +
+		"ERROR:\tconnection refused\n"
+	if tw.contents != expected {
+		t.Fatalf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithSampling_Close(t *testing.T) {
+	tw := &testWriter{}
+	exited := false
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithSampling(1, 0, time.Hour),
+		pocketlog.WithExitFunc(func(int) { exited = true }))
+
+	for i := 0; i < 5; i++ {
+		testedLogger.Warnw("disk almost full", "i", i)
+		testedLogger.Fatalf("out of disk")
+	}
+	testedLogger.Close()
+
+	if got := strings.Count(tw.contents, "FATAL:\tout of disk\n"); got != 5 || !exited {
+		t.Errorf("expected fatal entries to never be dropped, got %d in %q", got, tw.contents)
+	}
+	if !strings.HasSuffix(tw.contents, "WARN:\tsuppressed 4 similar messages template=\"disk almost full\" suppressed=4\n") {
+		t.Errorf("expected Close to log the summary, got %q", tw.contents)
+	}
+}
+
+func TestLogger_WithSampling_Ticker(t *testing.T) {
+	hooked := pocketlog.HookFunc(func(e pocketlog.Entry) (pocketlog.Entry, bool) {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], pocketlog.Bool("hooked", true))
+		return e, true
+	})
+
+	testedLogger, rec := pocketlogtest.NewLogger(t, pocketlog.WithSampling(1, 0, 50*time.Millisecond),
+		pocketlog.WithHook(pocketlog.LevelDebug, hooked))
+	defer testedLogger.Close()
+
+	for i := 0; i < 5; i++ {
+		testedLogger.Warnf("disk almost full")
+	}
+
+	// no entry follows the flood, yet the summary is logged at the end of the interval, through the hooks
+	summary := []pocketlogtest.Query{pocketlogtest.MessageContains("suppressed 4 similar messages"), pocketlogtest.HasField("hooked", true)}
+	deadline := time.Now().Add(5 * time.Second)
+	for rec.Count(summary...) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the summary")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := rec.Count(pocketlogtest.AtLevel(pocketlog.LevelWarn)); got != 2 {
+		t.Errorf("expected an entry and its summary, got %d entries", got)
+	}
+}
@@ -0,0 +1,110 @@
+package pocketlog
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrSinkPanicked is reported to the error handler when a sink panics
+const ErrSinkPanicked = pocketlogError("sink panicked")
+
+// Sink receives the entries of a logger, and writes them to their destination.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	// Enabled reports whether the sink writes entries of the given level.
+	Enabled(lvl Level) bool
+	// Log writes the entry. It must not modify the entry, which is shared with other sinks.
+	// Values are read with Field.Any.
+	Log(e Entry) error
+}
+
+// SinkFlusher is implemented by sinks that buffer entries before they write them.
+// Logger.Flush, Logger.Close and the Fatal methods call Flush, which returns once the buffered entries
+// are written, or once it gives up. Logger.Close also closes the sinks that implement io.Closer.
+type SinkFlusher interface {
+	Flush() error
+}
+
+// logTo hands the entry to the sink, turning a panic of the sink into an error.
+func logTo(s Sink, e Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrSinkPanicked, r)
+		}
+	}()
+
+	return s.Log(e)
+}
+
+// writerSink encodes entries and writes them to an io.Writer.
+type writerSink struct {
+	output    io.Writer
+	threshold Level
+	encoder   encoder
+
+	// mu serializes writes to output.
+	mu sync.Mutex
+}
+
+// NewWriterSink returns a sink that writes entries at or above threshold to output, one per line.
+// Lines are encoded according to the options, such as WithFormat.
+func NewWriterSink(output io.Writer, threshold Level, opts ...EncodingOption) Sink {
+	return newWriterSink(output, threshold, newEncoder(opts))
+}
+
+// newEncoder returns the encoder that the options set.
+func newEncoder(opts []EncodingOption) encoder {
+	var enc encoder
+	for _, configFunc := range opts {
+		configFunc(&enc)
+	}
+	return enc
+}
+
+// newWriterSink returns a sink that writes entries at or above threshold to output,
+// resolving the color mode of the encoder for the output.
+func newWriterSink(output io.Writer, threshold Level, enc encoder) *writerSink {
+	enc.color = colorOutput(enc.colorMode, output)
+	return &writerSink{output: output, threshold: threshold, encoder: enc}
+}
+
+// Enabled implements the Sink interface.
+func (s *writerSink) Enabled(lvl Level) bool {
+	return lvl >= s.threshold
+}
+
+// Log implements the Sink interface. It encodes the entry and hands it to the output in a single Write.
+func (s *writerSink) Log(e Entry) error {
+	buf := bufferPool.Get().(*[]byte)
+	defer putBuffer(buf)
+
+	// encode outside the lock, so that only the Write is serialized
+	*buf = s.encoder.encode((*buf)[:0], e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.output.Write(*buf)
+	return err
+}
+
+// maxPooledBuffer is the capacity above which buffers are not reused, so that a few huge entries
+// don't keep their memory forever.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers that writer sinks encode entries into.
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// putBuffer returns the buffer to the pool, unless it grew too large.
+// Writers don't retain the slices they are given, so buffers can be reused once written.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
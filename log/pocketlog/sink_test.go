@@ -0,0 +1,134 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"log/pocketlog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithSink(t *testing.T) {
+	text := &testWriter{}
+	json := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithSink(
+			pocketlog.NewWriterSink(text, pocketlog.LevelDebug, pocketlog.WithPrefix("> ")),
+			pocketlog.NewWriterSink(json, pocketlog.LevelWarn, pocketlog.WithFormat(pocketlog.FormatJSON)),
+		))
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Errorw(errorMessage, "code", 7)
+
+	expected := "> DEBUG:\t" + debugMessage + "\n" + "> ERROR:\t" + errorMessage + " code=7\n"
+	if text.contents != expected {
+		t.Errorf("invalid text contents, expected %q, got %q", expected, text.contents)
+	}
+
+	expectedJSON := `"level":"error","message":"` + errorMessage + `","fields":{"code":7}}` + "\n"
+	if strings.Count(json.contents, "\n") != 1 || !strings.HasSuffix(json.contents, expectedJSON) {
+		t.Errorf("invalid JSON contents, expected a single line ending with %q, got %q", expectedJSON, json.contents)
+	}
+}
+
+// failingSink is a Sink that always fails, or panics.
+type failingSink struct {
+	panics bool
+}
+
+// Enabled implements the pocketlog.Sink interface
+func (fs failingSink) Enabled(pocketlog.Level) bool {
+	return true
+}
+
+// Log implements the pocketlog.Sink interface
+func (fs failingSink) Log(pocketlog.Entry) error {
+	if fs.panics {
+		panic("sink on fire")
+	}
+	return errSinkDown
+}
+
+var errSinkDown = errors.New("sink down")
+
+func TestLogger_WithSink_Errors(t *testing.T) {
+	tw := &testWriter{}
+	var errs []error
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithSink(failingSink{}, pocketlog.NewWriterSink(tw, pocketlog.LevelInfo), failingSink{panics: true}),
+		pocketlog.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	testedLogger.Infof(infoMessage)
+
+	// the failing sinks must not prevent the others from writing
+	if tw.contents != "INFO:\t"+infoMessage+"\n" {
+		t.Errorf("invalid contents, expected %q, got %q", "INFO:\t"+infoMessage+"\n", tw.contents)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !errors.Is(errs[0], errSinkDown) || !strings.HasPrefix(errs[0].Error(), "sink 0:") {
+		t.Errorf("expected error of sink 0 to wrap %v, got %v", errSinkDown, errs[0])
+	}
+	if !errors.Is(errs[1], pocketlog.ErrSinkPanicked) || !strings.HasPrefix(errs[1].Error(), "sink 2:") {
+		t.Errorf("expected error of sink 2 to wrap %v, got %v", pocketlog.ErrSinkPanicked, errs[1])
+	}
+}
+
+// bufferedSink is a Sink that holds entries until it's flushed, and records when it's closed.
+type bufferedSink struct {
+	buffered []string
+	written  []string
+	closed   int
+}
+
+// Enabled implements the pocketlog.Sink interface
+func (bs *bufferedSink) Enabled(pocketlog.Level) bool {
+	return true
+}
+
+// Log implements the pocketlog.Sink interface
+func (bs *bufferedSink) Log(e pocketlog.Entry) error {
+	bs.buffered = append(bs.buffered, e.Message)
+	return nil
+}
+
+// Flush implements the pocketlog.SinkFlusher interface
+func (bs *bufferedSink) Flush() error {
+	bs.written = append(bs.written, bs.buffered...)
+	bs.buffered = nil
+	return nil
+}
+
+// Close implements the io.Closer interface
+func (bs *bufferedSink) Close() error {
+	bs.closed++
+	return bs.Flush()
+}
+
+func TestLogger_WithSink_Flush(t *testing.T) {
+	bs := &bufferedSink{}
+	var written []string
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithSink(bs),
+		pocketlog.WithExitFunc(func(int) { written = bs.written }))
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Named("child").Fatalf(errorMessage)
+
+	// the fatal entry is written before the exit function is called
+	if expected := []string{infoMessage, errorMessage}; !reflect.DeepEqual(written, expected) {
+		t.Errorf("expected %q to be written before exiting, got %q", expected, written)
+	}
+
+	testedLogger.Infof(debugMessage)
+	testedLogger.Close()
+	testedLogger.Named("child").Close()
+
+	if bs.closed != 1 || len(bs.buffered) != 0 {
+		t.Errorf("expected the sink to be closed once, with nothing left buffered, got %d closes and %q", bs.closed, bs.buffered)
+	}
+}
@@ -0,0 +1,161 @@
+package pocketlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// loggerKey is the key of the attribute that holds the name of a logger.
+const loggerKey = "logger"
+
+// slogLevelFatal is the slog level of LevelFatal entries, as slog has no such level.
+const slogLevelFatal = slog.LevelError + 4
+
+// ToSlogLevel returns the log/slog level that matches lvl.
+func ToSlogLevel(lvl Level) slog.Level {
+	switch lvl {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slogLevelFatal
+	}
+}
+
+// FromSlogLevel returns the level that matches a log/slog level.
+// Levels between two slog levels are rounded down, so slog.LevelInfo+2 becomes LevelInfo.
+func FromSlogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelInfo:
+		return LevelDebug
+	case lvl < slog.LevelWarn:
+		return LevelInfo
+	case lvl < slog.LevelError:
+		return LevelWarn
+	case lvl < slogLevelFatal:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+// slogSink hands entries to a log/slog handler.
+type slogSink struct {
+	handler slog.Handler
+}
+
+// Enabled implements the Sink interface.
+func (s slogSink) Enabled(lvl Level) bool {
+	return s.handler.Enabled(context.Background(), ToSlogLevel(lvl))
+}
+
+// Log implements the Sink interface. It turns the entry into a slog record, with an attribute for each field.
+func (s slogSink) Log(e Entry) error {
+	r := slog.NewRecord(e.Time, ToSlogLevel(e.Level), e.Message, e.PC)
+	if e.Name != "" {
+		r.AddAttrs(slog.String(loggerKey, e.Name))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(slog.Any(f.Key, f.Any()))
+	}
+
+	return s.handler.Handle(context.Background(), r)
+}
+
+// slogHandler is a log/slog handler that writes through a Logger.
+type slogHandler struct {
+	lgr *Logger
+	// fields were added by WithAttrs, and already carry the group prefix.
+	fields []Field
+	// prefix is the dot-separated list of the open groups, followed by a dot.
+	prefix string
+}
+
+// NewSlogHandler returns a log/slog handler that writes records through lgr,
+// with the threshold, fields and output of lgr. Attributes become fields,
+// and the keys of attributes in groups are prefixed with the group names, separated by dots.
+func NewSlogHandler(lgr *Logger) slog.Handler {
+	return &slogHandler{lgr: lgr}
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *slogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return h.lgr.Enabled(FromSlogLevel(lvl))
+}
+
+// Handle implements the slog.Handler interface.
+// The fields of the context are added after the fields of the logger, as with InfowCtx.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ctxFields := h.lgr.contextFields(ctx)
+	fields := make([]Field, 0, len(h.lgr.fields)+len(ctxFields)+len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.lgr.fields...)
+	fields = append(fields, ctxFields...)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	e := Entry{
+		Time:     r.Time,
+		Level:    FromSlogLevel(r.Level),
+		Name:     h.lgr.name,
+		Message:  r.Message,
+		template: r.Message,
+		Fields:   fields,
+	}
+	if h.lgr.caller {
+		e.PC = r.PC
+	}
+
+	h.lgr.log(e)
+	return nil
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	child.fields = h.fields[:len(h.fields):len(h.fields)]
+	for _, a := range attrs {
+		child.fields = appendAttr(child.fields, h.prefix, a)
+	}
+	return &child
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+// appendAttr appends the attribute as a field, or one field per attribute of a group.
+// Empty attributes and empty groups are ignored, as slog handlers should.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		return append(fields, Any(prefix+a.Key, a.Value.Any()))
+	}
+
+	// a group with an empty key is inlined
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		fields = appendAttr(fields, prefix, ga)
+	}
+	return fields
+}
@@ -0,0 +1,115 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/pocketlog"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestNewSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(buf), pocketlog.WithFormat(pocketlog.FormatJSON))
+
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+
+			var jl struct {
+				Time    string         `json:"time"`
+				Level   string         `json:"level"`
+				Message string         `json:"message"`
+				Fields  map[string]any `json:"fields"`
+			}
+			if err := json.Unmarshal(line, &jl); err != nil {
+				t.Fatalf("unable to decode %q: %s", line, err)
+			}
+
+			m := map[string]any{slog.LevelKey: jl.Level, slog.MessageKey: jl.Message}
+			if jl.Time != "" {
+				m[slog.TimeKey] = jl.Time
+			}
+			// slogtest expects groups as nested maps, and the handler flattens them with dots
+			for key, value := range jl.Fields {
+				nested := m
+				path := strings.Split(key, ".")
+				for _, group := range path[:len(path)-1] {
+					if _, ok := nested[group].(map[string]any); !ok {
+						nested[group] = map[string]any{}
+					}
+					nested = nested[group].(map[string]any)
+				}
+				nested[path[len(path)-1]] = value
+			}
+			ms = append(ms, m)
+		}
+		return ms
+	}
+
+	if err := slogtest.TestHandler(pocketlog.NewSlogHandler(lgr), results); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewSlogHandler_Levels(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelWarn, pocketlog.WithOutput(tw)).With("component", "db")
+
+	logger := slog.New(pocketlog.NewSlogHandler(lgr)).WithGroup("req").With("id", 7)
+	logger.Info(infoMessage)
+	logger.Warn(debugMessage, "attempt", 2)
+	logger.Log(context.Background(), slog.LevelError+4, errorMessage)
+
+	expected := "WARN:\t" + debugMessage + " component=db req.id=7 req.attempt=2\n" +
+		"FATAL:\t" + errorMessage + " component=db req.id=7\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestWithSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSlogHandler(handler)).With("request_id", "abc-123")
+
+	// the handler filters out debug entries on its own
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Infow(infoMessage, "user_id", 42)
+	testedLogger.Warnf("careful")
+	testedLogger.Errorf(errorMessage)
+
+	expected := `level=INFO msg="` + infoMessage + `" request_id=abc-123 user_id=42` + "\n" +
+		`level=WARN msg=careful request_id=abc-123` + "\n" +
+		`level=ERROR msg="` + errorMessage + `" request_id=abc-123` + "\n"
+	if buf.String() != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	for lvl := pocketlog.LevelDebug; lvl <= pocketlog.LevelFatal; lvl++ {
+		if got := pocketlog.FromSlogLevel(pocketlog.ToSlogLevel(lvl)); got != lvl {
+			t.Errorf("expected %v to map back to itself, got %v", lvl, got)
+		}
+	}
+
+	if got := pocketlog.FromSlogLevel(slog.LevelInfo + 2); got != pocketlog.LevelInfo {
+		t.Errorf("expected %v, got %v", pocketlog.LevelInfo, got)
+	}
+}
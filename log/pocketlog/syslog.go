@@ -0,0 +1,277 @@
+package pocketlog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Facility is the syslog facility of the program that logs entries, as defined by RFC 5424.
+type Facility byte
+
+// Facilities that programs usually log under.
+const (
+	FacilityKern   Facility = 0
+	FacilityUser   Facility = 1
+	FacilityDaemon Facility = 3
+	FacilityAuth   Facility = 4
+	FacilityLocal0 Facility = 16
+	FacilityLocal1 Facility = 17
+	FacilityLocal2 Facility = 18
+	FacilityLocal3 Facility = 19
+	FacilityLocal4 Facility = 20
+	FacilityLocal5 Facility = 21
+	FacilityLocal6 Facility = 22
+	FacilityLocal7 Facility = 23
+)
+
+// Severity returns the syslog severity of the level, from 2 (critical) for LevelFatal
+// to 7 (debug) for LevelDebug.
+func (lvl Level) Severity() int {
+	switch lvl {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// syslogTimeLayout is the timestamp layout of RFC 5424, which allows up to 6 digits for fractions of seconds.
+const syslogTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// syslogSDID is the ID of the structured data element that holds the fields of entries.
+// 32473 is the private enterprise number reserved for documentation by RFC 5612.
+const syslogSDID = "fields@32473"
+
+// syslogConfig holds the settings shared by the syslog and journald sinks.
+type syslogConfig struct {
+	facility Facility
+	appName  string
+	hostname string
+}
+
+// SyslogOption defines a functional option to the syslog and journald sinks
+type SyslogOption func(*syslogConfig)
+
+// WithFacility returns a configuration function that sets the facility of entries.
+// The default facility is FacilityUser.
+func WithFacility(facility Facility) SyslogOption {
+	return func(cfg *syslogConfig) {
+		cfg.facility = facility
+	}
+}
+
+// WithAppName returns a configuration function that sets the name of the program that logs entries.
+// The default name is the base name of the executable.
+func WithAppName(name string) SyslogOption {
+	return func(cfg *syslogConfig) {
+		cfg.appName = name
+	}
+}
+
+// WithHostname returns a configuration function that sets the host name of syslog messages.
+// The default host name is the one reported by the kernel. Journald ignores this setting.
+func WithHostname(hostname string) SyslogOption {
+	return func(cfg *syslogConfig) {
+		cfg.hostname = hostname
+	}
+}
+
+// newSyslogConfig returns the settings with the given options applied over the defaults.
+func newSyslogConfig(opts []SyslogOption) syslogConfig {
+	cfg := syslogConfig{facility: FacilityUser, appName: filepath.Base(os.Args[0])}
+	cfg.hostname, _ = os.Hostname()
+
+	for _, configFunc := range opts {
+		configFunc(&cfg)
+	}
+
+	return cfg
+}
+
+// SyslogSink is a Sink that sends entries as RFC 5424 syslog messages.
+// Fields are sent as the parameters of a structured data element.
+// A SyslogSink is safe for concurrent use by multiple goroutines.
+type SyslogSink struct {
+	network   string
+	address   string
+	threshold Level
+	cfg       syslogConfig
+
+	// mu protects conn, and serializes messages.
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink connects to the syslog server at address, and returns a sink that sends
+// entries at or above threshold. The network can be "udp", "tcp", "unix" or "unixgram", and their variants.
+// Messages are framed with octet counting, as per RFC 6587, over stream networks.
+func NewSyslogSink(network, address string, threshold Level, opts ...SyslogOption) (*SyslogSink, error) {
+	s := &SyslogSink{
+		network:   network,
+		address:   address,
+		threshold: threshold,
+		cfg:       newSyslogConfig(opts),
+	}
+
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Enabled implements the Sink interface.
+func (s *SyslogSink) Enabled(lvl Level) bool {
+	return lvl >= s.threshold
+}
+
+// Log implements the Sink interface. If sending the message fails, it reconnects and tries once more.
+func (s *SyslogSink) Log(e Entry) error {
+	msg := s.format(e)
+	if !isDatagram(s.network) {
+		msg = append(strconv.AppendInt(nil, int64(len(msg)), 10), append([]byte{' '}, msg...)...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if _, err := s.conn.Write(msg); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.dial(); err != nil {
+		return err
+	}
+
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// Close closes the connection to the syslog server.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// dial connects to the syslog server. It must be called with mu held, or before the sink is shared.
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("unable to connect to syslog at %s %s: %w", s.network, s.address, err)
+	}
+
+	s.conn = conn
+	return nil
+}
+
+// format returns the RFC 5424 message of the entry, without framing:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogSink) format(e Entry) []byte {
+	buf := make([]byte, 0, 128+len(e.Message))
+
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(s.cfg.facility)*8+int64(e.Level.Severity()), 10)
+	buf = append(buf, ">1 "...)
+
+	if e.Time.IsZero() {
+		buf = append(buf, '-')
+	} else {
+		buf = e.Time.AppendFormat(buf, syslogTimeLayout)
+	}
+	buf = append(buf, ' ')
+
+	buf = appendSyslogHeader(buf, s.cfg.hostname, 255)
+	buf = append(buf, ' ')
+	buf = appendSyslogHeader(buf, s.cfg.appName, 48)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(os.Getpid()), 10)
+	buf = append(buf, ' ')
+	// the name of the logger identifies the type of message
+	buf = appendSyslogHeader(buf, e.Name, 32)
+	buf = append(buf, ' ')
+
+	if len(e.Fields) == 0 {
+		buf = append(buf, '-')
+	} else {
+		buf = append(buf, '[')
+		buf = append(buf, syslogSDID...)
+		for _, f := range e.Fields {
+			buf = append(buf, ' ')
+			buf = appendSyslogHeader(buf, f.Key, 32)
+			buf = append(buf, `="`...)
+			buf = appendSyslogParamValue(buf, f.text())
+			buf = append(buf, '"')
+		}
+		buf = append(buf, ']')
+	}
+
+	if e.Message != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, e.Message...)
+	}
+
+	return buf
+}
+
+// appendSyslogHeader appends a header field or a parameter name, limited to maxLen printable ASCII characters.
+// Other characters, and the characters that delimit structured data, are replaced with underscores.
+// Empty values are replaced with the nil value, "-".
+func appendSyslogHeader(buf []byte, s string, maxLen int) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+
+	for i := 0; i < len(s) && i < maxLen; i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+// appendSyslogParamValue appends the value of a structured data parameter, escaping '"', '\' and ']'.
+func appendSyslogParamValue(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, s[i])
+	}
+	return buf
+}
+
+// isDatagram reports whether the network sends one message per packet.
+func isDatagram(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,111 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"log/pocketlog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSink_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer conn.Close()
+
+	sink, err := pocketlog.NewSyslogSink("udp", conn.LocalAddr().String(), pocketlog.LevelInfo,
+		pocketlog.WithFacility(pocketlog.FacilityLocal0), pocketlog.WithAppName("app"), pocketlog.WithHostname("host"))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	now := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink),
+		pocketlog.WithClock(func() time.Time { return now })).Named("http")
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Warnw(errorMessage, "path", `/a"b]`, "code", 7)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("unable to read message: %s", err)
+	}
+
+	// local0 is 16, warning is 4
+	expected := "<132>1 2024-03-01T12:30:00.123456Z host app " + strconv.Itoa(os.Getpid()) +
+		` http [fields@32473 path="/a\"b\]" code="7"] ` + errorMessage
+	if got := string(buf[:n]); got != expected {
+		t.Errorf("invalid message, expected %q, got %q", expected, got)
+	}
+}
+
+func TestSyslogSink_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	defer listener.Close()
+
+	sink, err := pocketlog.NewSyslogSink("tcp", listener.Addr().String(), pocketlog.LevelDebug,
+		pocketlog.WithAppName("app"), pocketlog.WithHostname("host"))
+	if err != nil {
+		t.Fatalf("unable to create sink: %s", err)
+	}
+	defer sink.Close()
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSink(sink))
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Errorf(errorMessage)
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %s", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// messages are framed with octet counting
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"<15>1 ", "<11>1 "} {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatalf("unable to read frame length: %s", err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatalf("invalid frame length %q", length)
+		}
+
+		msg := make([]byte, n)
+		if _, err := r.Read(msg); err != nil {
+			t.Fatalf("unable to read frame: %s", err)
+		}
+		if !strings.HasPrefix(string(msg), expected) {
+			t.Errorf("invalid message, expected prefix %q, got %q", expected, msg)
+		}
+	}
+}
+
+func TestLevel_Severity(t *testing.T) {
+	expected := map[pocketlog.Level]int{
+		pocketlog.LevelDebug: 7,
+		pocketlog.LevelInfo:  6,
+		pocketlog.LevelWarn:  4,
+		pocketlog.LevelError: 3,
+		pocketlog.LevelFatal: 2,
+	}
+
+	for lvl, severity := range expected {
+		if got := lvl.Severity(); got != severity {
+			t.Errorf("invalid severity for %s, expected %d, got %d", lvl, severity, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package pocketlog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// nameSeparator separates the names of nested named loggers.
+const nameSeparator = "."
+
+// thresholds holds the threshold of a logger and the overrides of its named children.
+// It is shared by a logger and all of its children, and is safe for concurrent use.
+type thresholds struct {
+	threshold atomic.Uint32
+
+	// overrides maps logger names to their threshold. It is never modified once stored:
+	// writers replace it with a copy, so that readers don't need a lock.
+	overrides atomic.Pointer[map[string]Level]
+	// mu serializes writers of overrides.
+	mu sync.Mutex
+}
+
+// newThresholds returns thresholds that let entries at or above threshold through.
+func newThresholds(threshold Level) *thresholds {
+	t := &thresholds{}
+	t.threshold.Store(uint32(threshold))
+	return t
+}
+
+// level returns the threshold of the logger with the given name: the override of the name,
+// or else of the closest parent name, or else the threshold shared by every logger.
+func (t *thresholds) level(name string) Level {
+	if overrides := t.overrides.Load(); overrides != nil && name != "" {
+		for {
+			if lvl, ok := (*overrides)[name]; ok {
+				return lvl
+			}
+
+			i := strings.LastIndex(name, nameSeparator)
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+
+	return Level(t.threshold.Load())
+}
+
+// set changes the threshold shared by every logger.
+func (t *thresholds) set(lvl Level) {
+	t.threshold.Store(uint32(lvl))
+}
+
+// setNamed changes the threshold of the loggers with the given name, and of their children.
+func (t *thresholds) setNamed(name string, lvl Level) {
+	t.update(func(overrides map[string]Level) {
+		overrides[name] = lvl
+	})
+}
+
+// unsetNamed removes the override of the given name.
+func (t *thresholds) unsetNamed(name string) {
+	t.update(func(overrides map[string]Level) {
+		delete(overrides, name)
+	})
+}
+
+// named returns a copy of the overrides.
+func (t *thresholds) named() map[string]Level {
+	overrides := t.overrides.Load()
+	if overrides == nil {
+		return map[string]Level{}
+	}
+
+	copied := make(map[string]Level, len(*overrides))
+	for name, lvl := range *overrides {
+		copied[name] = lvl
+	}
+	return copied
+}
+
+// update stores a modified copy of the overrides.
+func (t *thresholds) update(modify func(overrides map[string]Level)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	overrides := t.named()
+	modify(overrides)
+	t.overrides.Store(&overrides)
+}
@@ -0,0 +1,35 @@
+package pocketlog
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncate returns s, cut to at most maxBytes bytes on a rune boundary if it is longer,
+// followed by a marker that tells how many bytes were dropped.
+func truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut] + "...[truncated " + strconv.Itoa(len(s)-cut) + " bytes]"
+}
+
+// truncateEntry truncates the message of the entry, and the values of its fields that are made of text.
+func truncateEntry(e Entry, maxBytes int) Entry {
+	e.Message = truncate(e.Message, maxBytes)
+	e.Fields = replaceValues(e.Fields, func(f Field) (any, bool) {
+		text, ok := f.textValue()
+		if !ok || len(text) <= maxBytes {
+			return nil, false
+		}
+		return truncate(text, maxBytes), true
+	})
+
+	return e
+}
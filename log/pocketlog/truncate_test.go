@@ -0,0 +1,81 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"log/pocketlog"
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithMaxMessageLength(t *testing.T) {
+	tt := map[string]struct {
+		format   pocketlog.Format
+		message  string
+		fields   []any
+		expected string
+	}{
+		"short message": {
+			format:   pocketlog.FormatText,
+			message:  "hello",
+			expected: "INFO:\thello\n",
+		},
+		"exact length": {
+			format:   pocketlog.FormatText,
+			message:  "hello, wor",
+			expected: "INFO:\thello, wor\n",
+		},
+		"ascii message": {
+			format:   pocketlog.FormatText,
+			message:  "hello, world",
+			expected: "INFO:\thello, wor...[truncated 2 bytes]\n",
+		},
+		"multi-byte runes": {
+			// each rune takes 3 bytes: the 4th one would be cut in its middle
+			message:  "世界世界世界",
+			format:   pocketlog.FormatText,
+			expected: "INFO:\t世界世...[truncated 9 bytes]\n",
+		},
+		"field values": {
+			format:   pocketlog.FormatText,
+			message:  "ok",
+			fields:   []any{"payload", strings.Repeat("x", 12), "err", errors.New("0123456789ab"), "count", 1234567890123},
+			expected: `INFO:` + "\t" + `ok payload="xxxxxxxxxx...[truncated 2 bytes]" err="0123456789...[truncated 2 bytes]" count=1234567890123` + "\n",
+		},
+		"json": {
+			format:   pocketlog.FormatJSON,
+			message:  "hello, world",
+			fields:   []any{"payload", []byte("0123456789ab")},
+			expected: `"message":"hello, wor...[truncated 2 bytes]","fields":{"payload":"0123456789...[truncated 2 bytes]"}}` + "\n",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithFormat(tc.format),
+				pocketlog.WithMaxMessageLength(10))
+
+			testedLogger.Infow(tc.message, tc.fields...)
+
+			if !strings.HasSuffix(tw.contents, tc.expected) {
+				t.Errorf("invalid contents, expected %q, got %q", tc.expected, tw.contents)
+			}
+		})
+	}
+}
+
+func TestLogger_WithMaxMessageLength_SharedFields(t *testing.T) {
+	tw := &testWriter{}
+
+	parent := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithMaxMessageLength(4)).With("k", "value")
+
+	// truncating the first entry must not modify the fields of the logger
+	parent.Infof("a")
+	parent.Infof("b")
+
+	expected := "INFO:\ta k=\"valu...[truncated 1 bytes]\"\n" + "INFO:\tb k=\"valu...[truncated 1 bytes]\"\n"
+	if tw.contents != expected {
+		t.Errorf("invalid contents, expected %q, got %q", expected, tw.contents)
+	}
+}